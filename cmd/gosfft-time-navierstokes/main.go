@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/davidkleiven/gosfft/sfft"
+)
+
+func main() {
+	nr := 256
+	nc := 256
+	numSteps := 20
+	vorticity := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := 2.0 * math.Pi * float64(i) / float64(nr)
+			x := 2.0 * math.Pi * float64(j) / float64(nc)
+			vorticity[i*nc+j] = math.Sin(x)*math.Sin(y) + 0.5*math.Cos(3.0*x)*math.Sin(2.0*y)
+		}
+	}
+
+	for _, nWork := range []int{1, 2, 4, 8} {
+		ns := sfft.NewNavierStokes2(nr, nc, nWork, [2]float64{2.0 * math.Pi, 2.0 * math.Pi})
+		ns.Viscosity = 1e-3
+		ns.Dt = 1e-3
+		ns.SetVorticity(vorticity)
+		start := time.Now()
+		ns.Run(numSteps)
+		ellapsed := time.Since(start)
+		fmt.Printf("Time %d workers: %s per step\n", nWork, ellapsed/time.Duration(numSteps))
+	}
+}
@@ -0,0 +1,136 @@
+package sfft
+
+import (
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+const (
+	// GonumBackend evaluates the 1D transforms with gonum's fourier package
+	GonumBackend = "gonum"
+
+	// NativeBackend evaluates the 1D transforms with the mixed radix kernels in this
+	// package
+	NativeBackend = "native"
+)
+
+// defaultBackend is the backend used by the constructors that do not take a backend
+const defaultBackend = GonumBackend
+
+// CmplxTransform is a 1D transform of complex sequences. Coefficients performs the
+// forward transform and Sequence the unnormalized inverse transform, such that a forward
+// transform followed by an inverse transform multiplies the data by the length. Both
+// methods store the result in dst, which may be equal to src. If dst is nil, a new
+// slice is allocated. *fourier.CmplxFFT from gonum implements the interface.
+type CmplxTransform interface {
+	Coefficients(dst, src []complex128) []complex128
+	Sequence(dst, src []complex128) []complex128
+	Len() int
+}
+
+// RealTransform is a 1D transform of real sequences of length n, where only the n/2+1
+// coefficients of the non-negative frequencies are stored. The conventions are the same
+// as for CmplxTransform, and *fourier.FFT from gonum implements the interface.
+type RealTransform interface {
+	Coefficients(dst []complex128, seq []float64) []complex128
+	Sequence(dst []float64, coeff []complex128) []float64
+	Len() int
+}
+
+// BatchTransform is implemented by complex transforms that can transform several
+// sequences at once. The b sequences are interleaved, such that element i of sequence j
+// is stored at data[i*b + j]. The transforms are performed in-place. FFT3 uses batched
+// transforms for the depth lines when enabled (see FFT3.SetBatchSize).
+type BatchTransform interface {
+	CoefficientsBatch(data []complex128, b int)
+	SequenceBatch(data []complex128, b int)
+}
+
+// Backend creates the 1D transforms that are used by the 1D, 2D and 3D FFT types. The
+// returned transforms are only used from one goroutine at the time, and may therefore
+// hold internal buffers.
+type Backend interface {
+	// NewCmplx returns a complex transform of length n
+	NewCmplx(n int) CmplxTransform
+
+	// NewReal returns a real transform of length n
+	NewReal(n int) RealTransform
+}
+
+type gonumBackend struct{}
+
+func (gonumBackend) NewCmplx(n int) CmplxTransform {
+	return fourier.NewCmplxFFT(n)
+}
+
+func (gonumBackend) NewReal(n int) RealTransform {
+	return fourier.NewFFT(n)
+}
+
+type nativeBackend struct{}
+
+func (nativeBackend) NewCmplx(n int) CmplxTransform {
+	return newNativeFFT(n)
+}
+
+func (nativeBackend) NewReal(n int) RealTransform {
+	return newNativeRealFFT(n)
+}
+
+// registry holds the backends that can be selected by name
+var registry = struct {
+	sync.RWMutex
+	backends map[string]Backend
+}{
+	backends: map[string]Backend{
+		GonumBackend:  gonumBackend{},
+		NativeBackend: nativeBackend{},
+	},
+}
+
+// RegisterBackend makes a backend available under the given name, such that it can be
+// passed to the New...With constructors (e.g. NewFFT2With). It panics if the name is
+// empty or if a backend with the same name is already registered.
+func RegisterBackend(name string, backend Backend) {
+	if name == "" || backend == nil {
+		panic("sfft: A backend has to have a name")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exists := registry.backends[name]; exists {
+		panic("sfft: Backend " + name + " is already registered")
+	}
+	registry.backends[name] = backend
+}
+
+// LookupBackend returns the backend registered with the given name. The second return
+// value is false if no such backend exists
+func LookupBackend(name string) (Backend, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	backend, ok := registry.backends[name]
+	return backend, ok
+}
+
+// Backends returns the sorted names of all registered backends
+func Backends() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.backends))
+	for name := range registry.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mustBackend returns the backend with the given name and panics if it does not exist
+func mustBackend(name string) Backend {
+	backend, ok := LookupBackend(name)
+	if !ok {
+		panic("sfft: Unknown backend " + name)
+	}
+	return backend
+}
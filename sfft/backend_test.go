@@ -0,0 +1,82 @@
+package sfft
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// testBackends are the backends used by the tests of the FFT types
+var testBackends = []string{GonumBackend, NativeBackend}
+
+// countingBackend wraps the gonum transforms and counts the number of transforms created
+type countingBackend struct {
+	cmplx []int
+	real  []int
+}
+
+func (c *countingBackend) NewCmplx(n int) CmplxTransform {
+	c.cmplx = append(c.cmplx, n)
+	return fourier.NewCmplxFFT(n)
+}
+
+func (c *countingBackend) NewReal(n int) RealTransform {
+	c.real = append(c.real, n)
+	return fourier.NewFFT(n)
+}
+
+func TestRegisterBackend(t *testing.T) {
+	// The registry grows with each registration, which gives a unique name when the test
+	// is repeated
+	name := fmt.Sprintf("counting-%d", len(Backends()))
+	backend := &countingBackend{}
+	RegisterBackend(name, backend)
+
+	if b, ok := LookupBackend(name); !ok || b != backend {
+		t.Errorf("Expected the registered backend to be found")
+	}
+	found := false
+	for _, n := range Backends() {
+		found = found || n == name
+	}
+	if !found {
+		t.Errorf("Expected %s in %v", name, Backends())
+	}
+
+	rng := rand.New(rand.NewSource(17))
+	nr, nc, nd := 4, 6, 3
+	x := randomCmplx(rng, nr*nc*nd)
+	got := NewFFT3With(nr, nc, nd, name).FFT(append([]complex128{}, x...))
+	want := NewFFT3(nr, nc, nd).FFT(append([]complex128{}, x...))
+	if !cmplxSliceEqualApprox(got, want, 1e-10) {
+		t.Errorf("Transform with registered backend differs")
+	}
+	NewFFT2ParWith(nr, nc, 2, name)
+	NewFFT1With(8, name)
+	if len(backend.cmplx) != 3+2*2 || len(backend.real) != 1 {
+		t.Errorf("Unexpected number of transforms created: %v %v", backend.cmplx, backend.real)
+	}
+}
+
+func TestRegisterBackendTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic when a name is registered twice")
+		}
+	}()
+	RegisterBackend(GonumBackend, gonumBackend{})
+}
+
+func TestUnknownBackend(t *testing.T) {
+	if _, ok := LookupBackend("unknown"); ok {
+		t.Errorf("Expected no backend named unknown")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for an unknown backend")
+		}
+	}()
+	NewFFT2With(4, 4, "unknown")
+}
@@ -0,0 +1,137 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// CZT evaluates the chirp-z transform of a sequence x of length N
+//
+//	X_k = sum_n x_n A^-n W^nk,  k = 0, ..., M-1
+//
+// which is the z-transform evaluated at the M points z_k = A W^-k on a spiral contour in
+// the complex plane. A is the starting point and W the ratio between the points. The
+// transform is computed with Bluestein's algorithm as a convolution, which is evaluated
+// with FFTs of a power of two length L >= N + M - 1. The FFT plans are obtained from a
+// cache that is shared by all transforms of the same length, and a CZT can therefore be
+// used from multiple goroutines.
+type CZT struct {
+	n     int
+	m     int
+	l     int
+	pre   []complex128
+	post  []complex128
+	chirp []complex128
+}
+
+// NewCZT returns a new chirp-z transform mapping sequences of length n to m points on the
+// contour given by a and w
+func NewCZT(n, m int, w, a complex128) *CZT {
+	if n < 1 || m < 1 {
+		panic("czt: The length of the input and the output has to be positive")
+	}
+	if w == 0.0 || a == 0.0 {
+		panic("czt: A and W has to be non-zero")
+	}
+	c := &CZT{
+		n:    n,
+		m:    m,
+		l:    nextPow2(n + m - 1),
+		pre:  make([]complex128, n),
+		post: make([]complex128, m),
+	}
+
+	// Powers of W and A are evaluated as exp(t*log(W)) which makes the exponents
+	// consistent for non-integer t
+	logW := cmplx.Log(w)
+	logA := cmplx.Log(a)
+	wPow := func(t float64) complex128 {
+		return cmplx.Exp(complex(t, 0.0) * logW)
+	}
+	for i := range c.pre {
+		t := 0.5 * float64(i) * float64(i)
+		c.pre[i] = cmplx.Exp(complex(t, 0.0)*logW - complex(float64(i), 0.0)*logA)
+	}
+	for k := range c.post {
+		c.post[k] = wPow(0.5 * float64(k) * float64(k))
+	}
+
+	// Transform of the chirp W^(-j^2/2) for j = -(n-1), ..., m-1 stored circularly
+	c.chirp = make([]complex128, c.l)
+	for j := 0; j < m; j++ {
+		c.chirp[j] = wPow(-0.5 * float64(j) * float64(j))
+	}
+	for j := 1; j < n; j++ {
+		c.chirp[c.l-j] = wPow(-0.5 * float64(j) * float64(j))
+	}
+	ft := getPlan(c.l, defaultBackend)
+	ft.Coefficients(c.chirp, c.chirp)
+	putPlan(ft)
+	return c
+}
+
+// Transform returns the chirp-z transform of data. The length of data has to match the
+// input length passed on initialization
+func (c *CZT) Transform(data []complex128) []complex128 {
+	if len(data) != c.n {
+		panic("czt: Inconsistent length of data")
+	}
+	work := make([]complex128, c.l)
+	for i, v := range data {
+		work[i] = v * c.pre[i]
+	}
+	ft := getPlan(c.l, defaultBackend)
+	ft.Coefficients(work, work)
+	for i, v := range c.chirp {
+		work[i] *= v
+	}
+	ft.Sequence(work, work)
+	putPlan(ft)
+
+	res := make([]complex128, c.m)
+	scale := complex(1.0/float64(c.l), 0.0)
+	for k := range res {
+		res[k] = work[k] * c.post[k] * scale
+	}
+	return res
+}
+
+// ZoomFFT evaluates the discrete time Fourier transform of a real sequence at m equally
+// spaced frequencies from f1 to f2 (both included)
+//
+//	X_k = sum_n x_n exp(-2 pi i f_k n/fs)
+//
+// where fs is the sample rate. When f_k coincides with a frequency of the FFT, X_k equals
+// the corresponding FFT coefficient. This gives a high resolution view of a narrow band
+// without zero-padding the sequence.
+type ZoomFFT struct {
+	czt *CZT
+	f1  float64
+	df  float64
+}
+
+// NewZoomFFT returns a new zoom FFT for sequences of length n evaluated at m frequencies
+// between f1 and f2. fs is the sample rate
+func NewZoomFFT(n, m int, f1, f2, fs float64) *ZoomFFT {
+	df := 0.0
+	if m > 1 {
+		df = (f2 - f1) / float64(m-1)
+	}
+	w := cmplx.Exp(complex(0.0, -2.0*math.Pi*df/fs))
+	a := cmplx.Exp(complex(0.0, 2.0*math.Pi*f1/fs))
+	return &ZoomFFT{
+		czt: NewCZT(n, m, w, a),
+		f1:  f1,
+		df:  df,
+	}
+}
+
+// Transform returns the transform of data at the frequencies given by Freq
+func (z *ZoomFFT) Transform(data []float64) []complex128 {
+	return z.czt.Transform(ToComplex(data))
+}
+
+// Freq returns the frequency corresponding to index i of the array returned by Transform
+func (z *ZoomFFT) Freq(i int) float64 {
+	return z.f1 + float64(i)*z.df
+}
@@ -0,0 +1,109 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestCZT(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for i, test := range []struct {
+		n, m int
+		w, a complex128
+	}{
+		{n: 8, m: 8, w: cmplx.Exp(complex(0.0, -2.0*math.Pi/8.0)), a: 1.0},
+		{n: 7, m: 12, w: cmplx.Rect(1.02, -0.3), a: cmplx.Rect(0.9, 0.4)},
+		{n: 20, m: 5, w: cmplx.Rect(0.99, 0.1), a: cmplx.Rect(1.1, -1.0)},
+		{n: 1, m: 3, w: cmplx.Rect(1.0, 0.2), a: 1.0},
+	} {
+		x := make([]complex128, test.n)
+		for j := range x {
+			x[j] = complex(rng.NormFloat64(), rng.NormFloat64())
+		}
+		want := make([]complex128, test.m)
+		for k := range want {
+			for j, v := range x {
+				want[k] += v * cmplx.Pow(test.a, complex(-float64(j), 0.0)) * cmplx.Pow(test.w, complex(float64(j*k), 0.0))
+			}
+		}
+		got := NewCZT(test.n, test.m, test.w, test.a).Transform(x)
+		if !cmplxSliceEqualApprox(got, want, 1e-9) {
+			t.Errorf("Test #%d: Expected\n%v\ngot\n%v", i, want, got)
+		}
+	}
+}
+
+func TestZoomFFTMatchesFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	n := 32
+	fs := 4.0
+	x := randomSeq(rng, n)
+	coeff := NewFFT1(n).FFT(x)
+
+	// Bins 3 to 9 of the FFT
+	z := NewZoomFFT(n, 7, 3.0*fs/float64(n), 9.0*fs/float64(n), fs)
+	got := z.Transform(x)
+	for k, v := range got {
+		if math.Abs(z.Freq(k)-float64(k+3)*fs/float64(n)) > 1e-12 {
+			t.Errorf("Unexpected frequency %f", z.Freq(k))
+		}
+		if !CmplxEqualApprox(v, coeff[k+3], 1e-10) {
+			t.Errorf("Bin %d: Expected %v got %v", k+3, coeff[k+3], v)
+		}
+	}
+}
+
+func TestZoomFFTResolvesTone(t *testing.T) {
+	// A tone between two FFT bins is located to within the zoom resolution
+	n := 64
+	fs := 1.0
+	f0 := 10.3 / float64(n)
+	x := make([]float64, n)
+	win := periodicHann(n)
+	for i := range x {
+		x[i] = win[i] * math.Cos(2.0*math.Pi*f0*float64(i))
+	}
+	m := 201
+	z := NewZoomFFT(n, m, 9.0/float64(n), 12.0/float64(n), fs)
+	spec := z.Transform(x)
+	best := 0
+	for k, v := range spec {
+		if cmplx.Abs(v) > cmplx.Abs(spec[best]) {
+			best = k
+		}
+	}
+	if math.Abs(z.Freq(best)-f0) > 0.5*(z.Freq(1)-z.Freq(0)) {
+		t.Errorf("Expected peak at %f got %f", f0, z.Freq(best))
+	}
+}
+
+func TestCZTConcurrent(t *testing.T) {
+	n, m := 16, 10
+	c := NewCZT(n, m, cmplx.Rect(1.0, -0.1), 1.0)
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(float64(i), -float64(i))
+	}
+	want := c.Transform(x)
+
+	var wg sync.WaitGroup
+	results := make([][]complex128, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(num int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				results[num] = c.Transform(x)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, res := range results {
+		if !cmplxSliceEqualApprox(res, want, 1e-12) {
+			t.Errorf("Worker %d: Result differs from serial transform", i)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package sfft
+
+// keep23 returns true if the coefficient at index i of a transform of length n is
+// retained by the 2/3-rule, e.g. if the integer wave number is less than n/3
+func keep23(i, n int) bool {
+	m := i
+	if i > n/2 {
+		m = n - i
+	}
+	return 3*m < n
+}
+
+// DealiasMask1 returns the 2/3-rule mask for a 1D spectrum of length n. Elements are true
+// for coefficients that should be kept. When the highest third of the wave numbers is
+// removed from two fields, their product (computed in real space) is free of aliasing
+// errors within the retained modes
+func DealiasMask1(n int) []bool {
+	mask := make([]bool, n)
+	for i := range mask {
+		mask[i] = keep23(i, n)
+	}
+	return mask
+}
+
+// DealiasMask2 returns the 2/3-rule mask for the spectrum of a 2D array with nr rows and
+// nc columns, in the same layout as the coefficients returned by FFT2
+func DealiasMask2(nr, nc int) []bool {
+	mask := make([]bool, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			mask[i*nc+j] = keep23(i, nr) && keep23(j, nc)
+		}
+	}
+	return mask
+}
+
+// DealiasMask3 returns the 2/3-rule mask for the spectrum of a 3D array, in the same
+// layout as the coefficients returned by FFT3
+func DealiasMask3(nr, nc, nd int) []bool {
+	mask := make([]bool, nr*nc*nd)
+	f := flattened3{nr: nr, nc: nc, nd: nd}
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				mask[f.Index(i, j, k)] = keep23(i, nr) && keep23(j, nc) && keep23(k, nd)
+			}
+		}
+	}
+	return mask
+}
+
+// ApplyMask sets all coefficients where the mask is false to zero
+func ApplyMask(coeff []complex128, mask []bool) []complex128 {
+	if len(coeff) != len(mask) {
+		panic("dealias: Inconsistent length of coefficients and mask")
+	}
+	for i := range coeff {
+		if !mask[i] {
+			coeff[i] = 0.0
+		}
+	}
+	return coeff
+}
+
+// newTransformer returns a transform for an array with the given shape. The shape is
+// given in memory order (e.g. {n}, {nr, nc} or {nd, nr, nc})
+func newTransformer(shape []int) complexTransformer {
+	switch len(shape) {
+	case 1:
+		return NewCFFT(shape[0])
+	case 2:
+		return NewFFT2(shape[0], shape[1])
+	case 3:
+		return NewFFT3(shape[1], shape[2], shape[0])
+	default:
+		panic("sfft: Only 1D, 2D and 3D arrays are supported")
+	}
+}
+
+// paddedProduct returns the product of a and b with the 3/2-rule. The spectra of a and b
+// are zero-padded to 3/2 times the size along each axis, the product is formed in real
+// space on the fine grid and the result is truncated back to the original size. Thus, the
+// result equals the exact product with the frequencies that can not be represented on the
+// original grid removed. The shape is given in memory order (see newTransformer)
+func paddedProduct(a, b []complex128, shape []int) []complex128 {
+	if len(a) != prod(shape) || len(b) != prod(shape) {
+		panic("dealias: Inconsistent length of the factors")
+	}
+	padded := make([]int, len(shape))
+	for i, n := range shape {
+		padded[i] = (3*n + 1) / 2
+	}
+	ft := newTransformer(shape)
+	ftPad := newTransformer(padded)
+
+	fine := func(data []complex128) []complex128 {
+		coeff := make([]complex128, len(data))
+		copy(coeff, data)
+		ft.FFT(coeff)
+		return ftPad.IFFT(resampleComplex(coeff, shape, padded))
+	}
+	aFine := fine(a)
+	bFine := fine(b)
+	n := float64(len(a))
+	for i := range aFine {
+		aFine[i] *= bFine[i] / complex(n*n, 0.0)
+	}
+	ftPad.FFT(aFine)
+	res := resampleComplex(aFine, padded, shape)
+	ft.IFFT(res)
+	m := complex(float64(len(aFine)), 0.0)
+	for i := range res {
+		res[i] /= m
+	}
+	return res
+}
+
+// realProduct returns the real part of the padded product of two real arrays
+func realProduct(a, b []float64, shape []int) []float64 {
+	return realScaled(paddedProduct(ToComplex(a), ToComplex(b), shape), 1)
+}
+
+// Product1 returns the dealiased product of two periodic real sequences using the
+// 3/2-rule (see CProduct1)
+func Product1(a, b []float64) []float64 {
+	return realProduct(a, b, []int{len(a)})
+}
+
+// Product2 returns the dealiased product of two periodic real 2D arrays with nr rows and
+// nc columns stored row-major, using the 3/2-rule (see CProduct1)
+func Product2(a, b []float64, nr, nc int) []float64 {
+	return realProduct(a, b, []int{nr, nc})
+}
+
+// Product3 returns the dealiased product of two periodic real 3D arrays using the
+// 3/2-rule (see CProduct1)
+func Product3(a, b *Mat3) *Mat3 {
+	nr, nc, nd := a.Dims()
+	return NewMat3(nr, nc, nd, realProduct(a.Data, b.Data, []int{nd, nr, nc}))
+}
+
+// CProduct1 returns the dealiased product of two periodic complex sequences using the
+// 3/2-rule. The spectra are zero-padded to 3/2 times the original length before the
+// product is formed, and the result is truncated back to the original length. Thus, the
+// result is the exact product with the frequencies that can not be represented on the
+// grid removed, instead of being aliased onto lower frequencies.
+func CProduct1(a, b []complex128) []complex128 {
+	return paddedProduct(a, b, []int{len(a)})
+}
+
+// CProduct2 returns the dealiased product of two periodic complex 2D arrays with nr rows
+// and nc columns stored row-major, using the 3/2-rule (see CProduct1)
+func CProduct2(a, b []complex128, nr, nc int) []complex128 {
+	return paddedProduct(a, b, []int{nr, nc})
+}
+
+// CProduct3 returns the dealiased product of two periodic complex 3D arrays using the
+// 3/2-rule (see CProduct1)
+func CProduct3(a, b *CMat3) *CMat3 {
+	nr, nc, nd := a.Dims()
+	return NewCMat3(nr, nc, nd, paddedProduct(a.Data, b.Data, []int{nd, nr, nc}))
+}
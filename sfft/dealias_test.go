@@ -0,0 +1,210 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func cmplxSliceEqualApprox(a, b []complex128, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !CmplxEqualApprox(a[i], b[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDealiasMask(t *testing.T) {
+	for i, test := range []struct {
+		n    int
+		want []bool
+	}{
+		{n: 6, want: []bool{true, true, false, false, false, true}},
+		{n: 8, want: []bool{true, true, true, false, false, false, true, true}},
+		{n: 9, want: []bool{true, true, true, false, false, false, false, true, true}},
+	} {
+		mask := DealiasMask1(test.n)
+		for j := range mask {
+			if mask[j] != test.want[j] {
+				t.Errorf("Test #%d: Expected %v got %v", i, test.want, mask)
+				break
+			}
+		}
+	}
+
+	nr, nc, nd := 6, 8, 9
+	m2 := DealiasMask2(nr, nc)
+	forEachFreq2(nr, nc, nc, func(idx int, fr, fc float64) {
+		if m2[idx] != (3.0*math.Abs(fr) < 1.0 && 3.0*math.Abs(fc) < 1.0) {
+			t.Errorf("2D: Unexpected mask at %d", idx)
+		}
+	})
+	m3 := DealiasMask3(nr, nc, nd)
+	forEachFreq3(nr, nc, nd, nc, func(idx int, fr, fc, fd float64) {
+		if m3[idx] != (3.0*math.Abs(fr) < 1.0 && 3.0*math.Abs(fc) < 1.0 && 3.0*math.Abs(fd) < 1.0) {
+			t.Errorf("3D: Unexpected mask at %d", idx)
+		}
+	})
+}
+
+func TestMaskedProductAliasFree(t *testing.T) {
+	// cos(2x)*cos(3x) = 0.5*cos(x) + 0.5*cos(5x). On a grid with 9 points cos(5x) aliases
+	// onto cos(4x). With the 2/3-rule the inputs are truncated to |k| < 3 and the product
+	// of the retained modes is free of aliasing within the retained modes
+	n := 9
+	a := make([]complex128, n)
+	b := make([]complex128, n)
+	for i := range a {
+		x := 2.0 * math.Pi * float64(i) / float64(n)
+		a[i] = complex(math.Cos(x)+math.Cos(2.0*x), 0.0)
+		b[i] = complex(math.Cos(2.0*x)+math.Cos(3.0*x), 0.0)
+	}
+	ft := NewCFFT(n)
+	mask := DealiasMask1(n)
+	ft.IFFT(ApplyMask(ft.FFT(a), mask))
+	ft.IFFT(ApplyMask(ft.FFT(b), mask))
+	p := make([]complex128, n)
+	for i := range p {
+		p[i] = a[i] * b[i] / complex(float64(n*n), 0.0)
+	}
+	ApplyMask(ft.FFT(p), mask)
+	got := realScaled(ft.IFFT(p), n)
+
+	// (cos(x) + cos(2x))*cos(2x) = 0.5 + 0.5*cos(x) + 0.5*cos(3x) + 0.5*cos(4x)
+	want := make([]float64, n)
+	for i := range want {
+		x := 2.0 * math.Pi * float64(i) / float64(n)
+		want[i] = 0.5 + 0.5*math.Cos(x)
+	}
+	if !floats.EqualApprox(got, want, 1e-10) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestProduct1(t *testing.T) {
+	for _, n := range []int{8, 9} {
+		a := make([]float64, n)
+		b := make([]float64, n)
+		want := make([]float64, n)
+		for i := range a {
+			x := 2.0 * math.Pi * float64(i) / float64(n)
+			a[i] = math.Cos(3.0 * x)
+			b[i] = math.Cos(2.0 * x)
+
+			// The cos(5x) term can not be represented and is removed
+			want[i] = 0.5 * math.Cos(x)
+		}
+		got := Product1(a, b)
+		if !floats.EqualApprox(got, want, 1e-10) {
+			t.Errorf("n=%d: Expected\n%v\ngot\n%v", n, want, got)
+		}
+
+		// The naive product contains the aliased term
+		naive := make([]float64, n)
+		floats.MulTo(naive, a, b)
+		if floats.EqualApprox(naive, want, 1e-3) {
+			t.Errorf("n=%d: Expected the naive product to be aliased", n)
+		}
+	}
+}
+
+func TestCProduct1(t *testing.T) {
+	n := 8
+	a := make([]complex128, n)
+	b := make([]complex128, n)
+	want := make([]complex128, n)
+	for i := range a {
+		x := 2.0 * math.Pi * float64(i) / float64(n)
+		a[i] = complex(math.Cos(3.0*x), math.Sin(3.0*x)) + complex(math.Cos(x), -math.Sin(x))
+		b[i] = complex(math.Cos(2.0*x), math.Sin(2.0*x))
+
+		// exp(5ix) is removed, exp(ix) is kept
+		want[i] = complex(math.Cos(x), math.Sin(x))
+	}
+	got := CProduct1(a, b)
+	if !cmplxSliceEqualApprox(got, want, 1e-10) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestProduct2(t *testing.T) {
+	nr, nc := 8, 6
+	a := make([]float64, nr*nc)
+	b := make([]float64, nr*nc)
+	want := make([]float64, nr*nc)
+	ca := make([]complex128, nr*nc)
+	cb := make([]complex128, nr*nc)
+	cwant := make([]complex128, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := 2.0 * math.Pi * float64(i) / float64(nr)
+			x := 2.0 * math.Pi * float64(j) / float64(nc)
+			idx := i*nc + j
+			a[idx] = math.Cos(3.0*y) * math.Cos(x)
+			b[idx] = math.Cos(2.0*y) * math.Cos(2.0*x)
+
+			// cos(5y) can not be represented, cos(3x) is the Nyquist frequency
+			want[idx] = 0.25 * math.Cos(y) * (math.Cos(x) + math.Cos(3.0*x))
+
+			ca[idx] = complex(math.Cos(3.0*y+2.0*x), math.Sin(3.0*y+2.0*x))
+			cb[idx] = complex(math.Cos(-2.0*y+x), math.Sin(-2.0*y+x))
+		}
+	}
+	got := Product2(a, b, nr, nc)
+	if !floats.EqualApprox(got, want, 1e-10) {
+		t.Errorf("Real: Expected\n%v\ngot\n%v", want, got)
+	}
+
+	// exp(i(y + 3x)) is kept since 3 is the Nyquist frequency of the columns
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := 2.0 * math.Pi * float64(i) / float64(nr)
+			x := 2.0 * math.Pi * float64(j) / float64(nc)
+			cwant[i*nc+j] = complex(math.Cos(y+3.0*x), math.Sin(y+3.0*x))
+		}
+	}
+	cgot := CProduct2(ca, cb, nr, nc)
+	if !cmplxSliceEqualApprox(cgot, cwant, 1e-10) {
+		t.Errorf("Complex: Expected\n%v\ngot\n%v", cwant, cgot)
+	}
+}
+
+func TestProduct3(t *testing.T) {
+	nr, nc, nd := 4, 6, 8
+	a := NewMat3(nr, nc, nd, nil)
+	b := NewMat3(nr, nc, nd, nil)
+	want := NewMat3(nr, nc, nd, nil)
+	ca := NewCMat3(nr, nc, nd, nil)
+	cb := NewCMat3(nr, nc, nd, nil)
+	cwant := NewCMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				y := 2.0 * math.Pi * float64(i) / float64(nr)
+				x := 2.0 * math.Pi * float64(j) / float64(nc)
+				z := 2.0 * math.Pi * float64(k) / float64(nd)
+				a.Set(i, j, k, math.Cos(y)*math.Cos(3.0*z))
+				b.Set(i, j, k, math.Cos(x)*math.Cos(2.0*z))
+				want.Set(i, j, k, 0.5*math.Cos(y)*math.Cos(x)*math.Cos(z))
+
+				ca.Set(i, j, k, complex(math.Cos(x+3.0*z), math.Sin(x+3.0*z)))
+				cb.Set(i, j, k, complex(math.Cos(y+2.0*z), math.Sin(y+2.0*z)))
+			}
+		}
+	}
+	got := Product3(a, b)
+	if !floats.EqualApprox(got.Data, want.Data, 1e-10) {
+		t.Errorf("Real: Product not alias free")
+	}
+
+	// exp(i(x + y + 5z)) can not be represented
+	cgot := CProduct3(ca, cb)
+	if !cmplxSliceEqualApprox(cgot.Data, cwant.Data, 1e-10) {
+		t.Errorf("Complex: Product not alias free")
+	}
+}
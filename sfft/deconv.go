@@ -0,0 +1,127 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// psfTransform returns the transform of the point spread function normalized to unit sum
+func psfTransform(psf []float64, ft complexTransformer) []complex128 {
+	sum := 0.0
+	for _, v := range psf {
+		sum += v
+	}
+	if sum == 0.0 {
+		panic("deconv: The point spread function has zero sum")
+	}
+	h := make([]complex128, len(psf))
+	for i, v := range psf {
+		h[i] = complex(v/sum, 0.0)
+	}
+	return ft.FFT(h)
+}
+
+// wiener applies the Wiener filter to an image with the given shape (see newTransformer)
+func wiener(img, psf []float64, shape []int, nsr float64) []float64 {
+	if len(img) != prod(shape) || len(psf) != len(img) {
+		panic("deconv: Inconsistent size of image and point spread function")
+	}
+	ft := newTransformer(shape)
+	h := psfTransform(psf, ft)
+	coeff := ft.FFT(ToComplex(img))
+	for i, v := range h {
+		a := cmplx.Abs(v)
+		coeff[i] *= cmplx.Conj(v) / complex(a*a+nsr, 0.0)
+	}
+	return realScaled(ft.IFFT(coeff), len(coeff))
+}
+
+// convolve returns the circular convolution of data with the kernel given by its
+// transform h. If conj is true, the correlation is returned instead
+func convolve(data []float64, h []complex128, ft complexTransformer, conj bool) []float64 {
+	coeff := ft.FFT(ToComplex(data))
+	for i, v := range h {
+		if conj {
+			v = cmplx.Conj(v)
+		}
+		coeff[i] *= v
+	}
+	return realScaled(ft.IFFT(coeff), len(coeff))
+}
+
+// richardsonLucy runs the Richardson-Lucy algorithm on an image with the given shape
+func richardsonLucy(img, psf []float64, shape []int, iterations int) []float64 {
+	if len(img) != prod(shape) || len(psf) != len(img) {
+		panic("deconv: Inconsistent size of image and point spread function")
+	}
+	ft := newTransformer(shape)
+	h := psfTransform(psf, ft)
+
+	// Start from a flat estimate with the same total intensity as the image
+	mean := 0.0
+	for _, v := range img {
+		mean += math.Max(v, 0.0)
+	}
+	mean /= float64(len(img))
+	est := make([]float64, len(img))
+	for i := range est {
+		est[i] = mean
+	}
+
+	ratio := make([]float64, len(img))
+	for iter := 0; iter < iterations; iter++ {
+		blurred := convolve(est, h, ft, false)
+		for i, v := range blurred {
+			if v > 1e-12*mean {
+				ratio[i] = math.Max(img[i], 0.0) / v
+			} else {
+				ratio[i] = 0.0
+			}
+		}
+		corr := convolve(ratio, h, ft, true)
+		for i := range est {
+			est[i] = math.Max(est[i]*corr[i], 0.0)
+		}
+	}
+	return est
+}
+
+// Wiener2 deconvolves a 2D image with nr rows and nc columns stored row-major using a
+// Wiener filter
+//
+//	X = conj(H) Y / (|H|^2 + nsr)
+//
+// where Y is the transform of the image and H the transform of the point spread function.
+// nsr is the noise-to-signal power ratio, which regularizes the inversion at frequencies
+// where H is small. The point spread function has the same size as the image and is
+// centered at index 0, with negative offsets wrapped around to the end of each axis.
+// It is normalized to unit sum. The image is assumed to be periodic.
+func Wiener2(img, psf []float64, nr, nc int, nsr float64) []float64 {
+	return wiener(img, psf, []int{nr, nc}, nsr)
+}
+
+// Wiener3 deconvolves a 3D stack using a Wiener filter (see Wiener2)
+func Wiener3(img, psf *Mat3, nsr float64) *Mat3 {
+	nr, nc, nd := img.Dims()
+	return NewMat3(nr, nc, nd, wiener(img.Data, psf.Data, []int{nd, nr, nc}, nsr))
+}
+
+// RichardsonLucy2 deconvolves a 2D image with nr rows and nc columns stored row-major
+// with the Richardson-Lucy algorithm. Each iteration updates the estimate u by
+//
+//	u <- u * (conj(h) * (img / (h * u)))
+//
+// where * denotes circular convolution (correlation for conj(h)) and the other
+// operations are element-wise. The estimate is non-negative, and negative pixels in the
+// image are treated as zero. The point spread function follows the same conventions as
+// for Wiener2.
+func RichardsonLucy2(img, psf []float64, nr, nc, iterations int) []float64 {
+	return richardsonLucy(img, psf, []int{nr, nc}, iterations)
+}
+
+// RichardsonLucy3 deconvolves a 3D stack with the Richardson-Lucy algorithm (see
+// RichardsonLucy2)
+func RichardsonLucy3(img, psf *Mat3, iterations int) *Mat3 {
+	nr, nc, nd := img.Dims()
+	return NewMat3(nr, nc, nd, richardsonLucy(img.Data, psf.Data, []int{nd, nr, nc}, iterations))
+}
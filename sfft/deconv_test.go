@@ -0,0 +1,127 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// wrappedGaussian returns a Gaussian point spread function centered at index 0 of an
+// array with the given shape (in memory order)
+func wrappedGaussian(shape []int, sigma float64) []float64 {
+	psf := make([]float64, prod(shape))
+	for idx := range psf {
+		r2 := 0.0
+		rem := idx
+		for axis := len(shape) - 1; axis >= 0; axis-- {
+			i := rem % shape[axis]
+			rem /= shape[axis]
+			if i > shape[axis]/2 {
+				i -= shape[axis]
+			}
+			r2 += float64(i * i)
+		}
+		psf[idx] = math.Exp(-0.5 * r2 / (sigma * sigma))
+	}
+	return psf
+}
+
+// blobs returns an image with smooth blobs on a constant background
+func blobs(nr, nc int) []float64 {
+	img := gaussian2(nr, nc, 10.0, 12.0, 2.0)
+	floats.AddScaled(img, 0.5, gaussian2(nr, nc, 20.0, 22.0, 3.0))
+	floats.AddScaled(img, 0.1, ones(nr*nc))
+	return img
+}
+
+// blur convolves the image with the point spread function
+func blur(img, psf []float64, shape []int) []float64 {
+	ft := newTransformer(shape)
+	return convolve(img, psfTransform(psf, ft), ft, false)
+}
+
+// rmsError returns the root mean square difference between a and b
+func rmsError(a, b []float64) float64 {
+	return floats.Distance(a, b, 2) / math.Sqrt(float64(len(a)))
+}
+
+func TestWiener2(t *testing.T) {
+	nr, nc := 32, 32
+	img := blobs(nr, nc)
+	psf := wrappedGaussian([]int{nr, nc}, 1.5)
+	blurred := blur(img, psf, []int{nr, nc})
+
+	res := Wiener2(blurred, psf, nr, nc, 1e-8)
+	if rmsError(res, img) > 0.01*rmsError(blurred, img) {
+		t.Errorf("Wiener filter did not recover the image. Error %e (blurred %e)", rmsError(res, img), rmsError(blurred, img))
+	}
+
+	// Without blur, the filter with nsr = 0 is the identity
+	delta := make([]float64, nr*nc)
+	delta[0] = 1.0
+	if !floats.EqualApprox(Wiener2(img, delta, nr, nc, 0.0), img, 1e-10) {
+		t.Errorf("Expected a delta function PSF to leave the image unchanged")
+	}
+}
+
+func TestWiener3(t *testing.T) {
+	nr, nc, nd := 16, 16, 8
+	img := NewMat3(nr, nc, nd, nil)
+	for k := 0; k < nd; k++ {
+		w := 0.5 + 0.5*math.Cos(2.0*math.Pi*float64(k)/float64(nd))
+		floats.AddScaled(img.Data[k*nr*nc:(k+1)*nr*nc], w, gaussian2(nr, nc, 7.0, 8.0, 2.5))
+	}
+	psf := NewMat3(nr, nc, nd, wrappedGaussian([]int{nd, nr, nc}, 1.0))
+	blurred := NewMat3(nr, nc, nd, blur(img.Data, psf.Data, []int{nd, nr, nc}))
+
+	res := Wiener3(blurred, psf, 1e-8)
+	if rmsError(res.Data, img.Data) > 0.01*rmsError(blurred.Data, img.Data) {
+		t.Errorf("Wiener filter did not recover the stack. Error %e (blurred %e)", rmsError(res.Data, img.Data), rmsError(blurred.Data, img.Data))
+	}
+}
+
+func TestRichardsonLucy2(t *testing.T) {
+	nr, nc := 32, 32
+	img := blobs(nr, nc)
+	psf := wrappedGaussian([]int{nr, nc}, 1.5)
+	blurred := blur(img, psf, []int{nr, nc})
+
+	prev := rmsError(blurred, img)
+	for _, iter := range []int{5, 20, 100} {
+		res := RichardsonLucy2(blurred, psf, nr, nc, iter)
+		err := rmsError(res, img)
+		if err >= prev {
+			t.Errorf("%d iterations: Expected the error to decrease. Got %e (previous %e)", iter, err, prev)
+		}
+		prev = err
+
+		if floats.Min(res) < 0.0 {
+			t.Errorf("%d iterations: Estimate has negative values", iter)
+		}
+
+		// The total intensity is preserved
+		if math.Abs(floats.Sum(res)-floats.Sum(blurred)) > 1e-8*floats.Sum(blurred) {
+			t.Errorf("%d iterations: Total intensity %f differs from %f", iter, floats.Sum(res), floats.Sum(blurred))
+		}
+	}
+	if prev > 0.2*rmsError(blurred, img) {
+		t.Errorf("Richardson-Lucy did not improve the image sufficiently. Error %e (blurred %e)", prev, rmsError(blurred, img))
+	}
+}
+
+func TestRichardsonLucy3(t *testing.T) {
+	nr, nc, nd := 16, 16, 8
+	img := NewMat3(nr, nc, nd, nil)
+	for k := 0; k < nd; k++ {
+		w := 0.6 + 0.4*math.Cos(2.0*math.Pi*float64(k)/float64(nd))
+		floats.AddScaled(img.Data[k*nr*nc:(k+1)*nr*nc], w, gaussian2(nr, nc, 7.0, 8.0, 2.0))
+	}
+	psf := NewMat3(nr, nc, nd, wrappedGaussian([]int{nd, nr, nc}, 1.0))
+	blurred := NewMat3(nr, nc, nd, blur(img.Data, psf.Data, []int{nd, nr, nc}))
+
+	res := RichardsonLucy3(blurred, psf, 50)
+	if rmsError(res.Data, img.Data) > 0.5*rmsError(blurred.Data, img.Data) || floats.Min(res.Data) < 0.0 {
+		t.Errorf("Richardson-Lucy did not recover the stack. Error %e (blurred %e)", rmsError(res.Data, img.Data), rmsError(blurred.Data, img.Data))
+	}
+}
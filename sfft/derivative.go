@@ -0,0 +1,180 @@
+package sfft
+
+import "math"
+
+// derivativeFactors returns the factors (ik)^order that the Fourier coefficients of a
+// periodic sequence of length n with spacing d are multiplied by when differentiated.
+// For even n, the Nyquist mode has no well defined sign. Its factor is therefore set
+// to zero for odd orders and to (ik)^order with k = pi/d for even orders, such that
+// real sequences have real derivatives. It panics if the order is negative
+func derivativeFactors(n int, d float64, order int) []complex128 {
+	if order < 0 {
+		panic("sfft: The order of the derivative has to be non-negative")
+	}
+	// Powers of the imaginary unit
+	iPow := [4]complex128{1.0, complex(0.0, 1.0), -1.0, complex(0.0, -1.0)}
+	factors := make([]complex128, n)
+	for i := range factors {
+		k := 2.0 * math.Pi * freqIndex(i, n) / d
+		if n%2 == 0 && i == n/2 && order%2 == 1 {
+			continue
+		}
+		factors[i] = iPow[order%4] * complex(math.Pow(k, float64(order)), 0.0)
+	}
+	return factors
+}
+
+// backTransform2 multiplies a copy of the 2D coefficients by mult(i, j), performs the
+// inverse transform and returns the real part
+func backTransform2(coeff []complex128, nr, nc int, mult func(i, j int) complex128) []float64 {
+	work := make([]complex128, len(coeff))
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			work[i*nc+j] = coeff[i*nc+j] * mult(i, j)
+		}
+	}
+	NewFFT2(nr, nc).IFFT(work)
+	return realScaled(work, len(work))
+}
+
+// backTransform3 is the 3D version of backTransform2
+func backTransform3(coeff *CMat3, mult func(i, j, k int) complex128) *Mat3 {
+	nr, nc, nd := coeff.Dims()
+	work := NewCMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				work.Set(i, j, k, coeff.At(i, j, k)*mult(i, j, k))
+			}
+		}
+	}
+	NewFFT3(nr, nc, nd).IFFT(work.Data)
+	return NewMat3(nr, nc, nd, realScaled(work.Data, len(work.Data)))
+}
+
+// forward2 returns the Fourier coefficients of a real 2D array
+func forward2(data []float64, nr, nc int) []complex128 {
+	if len(data) != nr*nc {
+		panic("sfft: Inconsistent size of 2D data")
+	}
+	return NewFFT2(nr, nc).FFT(ToComplex(data))
+}
+
+// forward3 returns the Fourier coefficients of a real 3D array
+func forward3(data *Mat3) *CMat3 {
+	nr, nc, nd := data.Dims()
+	return NewCMat3(nr, nc, nd, NewFFT3(nr, nc, nd).FFT(ToComplex(data.Data)))
+}
+
+// Derivative1 returns the derivative of the given order of a periodic sequence sampled
+// with spacing dx. The order has to be non-negative
+func Derivative1(data []float64, dx float64, order int) []float64 {
+	n := len(data)
+	coeff := ToComplex(data)
+	ft := NewCFFT(n)
+	ft.FFT(coeff)
+	factors := derivativeFactors(n, dx, order)
+	for i := range coeff {
+		coeff[i] *= factors[i]
+	}
+	ft.IFFT(coeff)
+	return realScaled(coeff, n)
+}
+
+// Derivative2 returns the mixed derivative of a periodic 2D array with nr rows and nc
+// columns (stored row-major). spacing holds the grid spacing along the rows (first
+// index) and the columns (second index), and order holds the order of the derivative
+// with respect to each of the two directions. The orders have to be non-negative
+func Derivative2(data []float64, nr, nc int, spacing [2]float64, order [2]int) []float64 {
+	fr := derivativeFactors(nr, spacing[0], order[0])
+	fc := derivativeFactors(nc, spacing[1], order[1])
+	return backTransform2(forward2(data, nr, nc), nr, nc, func(i, j int) complex128 {
+		return fr[i] * fc[j]
+	})
+}
+
+// Gradient2 returns the gradient of a periodic 2D array. The first item is the
+// derivative along the first index (rows) and the second item is the derivative
+// along the second index (columns)
+func Gradient2(data []float64, nr, nc int, spacing [2]float64) [2][]float64 {
+	coeff := forward2(data, nr, nc)
+	fr := derivativeFactors(nr, spacing[0], 1)
+	fc := derivativeFactors(nc, spacing[1], 1)
+	var grad [2][]float64
+	grad[0] = backTransform2(coeff, nr, nc, func(i, j int) complex128 { return fr[i] })
+	grad[1] = backTransform2(coeff, nr, nc, func(i, j int) complex128 { return fc[j] })
+	return grad
+}
+
+// Divergence2 returns the divergence of a periodic 2D vector field. field[0] is the
+// component along the first index (rows) and field[1] is the component along the
+// second index (columns)
+func Divergence2(field [2][]float64, nr, nc int, spacing [2]float64) []float64 {
+	dr := Derivative2(field[0], nr, nc, spacing, [2]int{1, 0})
+	dc := Derivative2(field[1], nr, nc, spacing, [2]int{0, 1})
+	for i := range dr {
+		dr[i] += dc[i]
+	}
+	return dr
+}
+
+// Laplacian2 returns the Laplacian of a periodic 2D array
+func Laplacian2(data []float64, nr, nc int, spacing [2]float64) []float64 {
+	fr := derivativeFactors(nr, spacing[0], 2)
+	fc := derivativeFactors(nc, spacing[1], 2)
+	return backTransform2(forward2(data, nr, nc), nr, nc, func(i, j int) complex128 {
+		return fr[i] + fc[j]
+	})
+}
+
+// Derivative3 returns the mixed derivative of a periodic 3D array. spacing holds
+// the grid spacing along the rows, the columns and the depth, respectively, and
+// order holds the order of the derivative with respect to each direction. The orders
+// have to be non-negative
+func Derivative3(data *Mat3, spacing [3]float64, order [3]int) *Mat3 {
+	nr, nc, nd := data.Dims()
+	fr := derivativeFactors(nr, spacing[0], order[0])
+	fc := derivativeFactors(nc, spacing[1], order[1])
+	fd := derivativeFactors(nd, spacing[2], order[2])
+	return backTransform3(forward3(data), func(i, j, k int) complex128 {
+		return fr[i] * fc[j] * fd[k]
+	})
+}
+
+// Gradient3 returns the gradient of a periodic 3D array. The items are the
+// derivatives along the rows, the columns and the depth, respectively
+func Gradient3(data *Mat3, spacing [3]float64) [3]*Mat3 {
+	nr, nc, nd := data.Dims()
+	coeff := forward3(data)
+	fr := derivativeFactors(nr, spacing[0], 1)
+	fc := derivativeFactors(nc, spacing[1], 1)
+	fd := derivativeFactors(nd, spacing[2], 1)
+	var grad [3]*Mat3
+	grad[0] = backTransform3(coeff, func(i, j, k int) complex128 { return fr[i] })
+	grad[1] = backTransform3(coeff, func(i, j, k int) complex128 { return fc[j] })
+	grad[2] = backTransform3(coeff, func(i, j, k int) complex128 { return fd[k] })
+	return grad
+}
+
+// Divergence3 returns the divergence of a periodic 3D vector field. The items of
+// field are the components along the rows, the columns and the depth, respectively
+func Divergence3(field [3]*Mat3, spacing [3]float64) *Mat3 {
+	div := Derivative3(field[0], spacing, [3]int{1, 0, 0})
+	dc := Derivative3(field[1], spacing, [3]int{0, 1, 0})
+	dd := Derivative3(field[2], spacing, [3]int{0, 0, 1})
+	for i := range div.Data {
+		div.Data[i] += dc.Data[i] + dd.Data[i]
+	}
+	return div
+}
+
+// Laplacian3 returns the Laplacian of a periodic 3D array
+func Laplacian3(data *Mat3, spacing [3]float64) *Mat3 {
+	nr, nc, nd := data.Dims()
+	fr := derivativeFactors(nr, spacing[0], 2)
+	fc := derivativeFactors(nc, spacing[1], 2)
+	fd := derivativeFactors(nd, spacing[2], 2)
+	return backTransform3(forward3(data), func(i, j, k int) complex128 {
+		return fr[i] + fc[j] + fd[k]
+	})
+}
@@ -0,0 +1,162 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestDerivative1(t *testing.T) {
+	length := 3.0
+	for _, n := range []int{16, 17} {
+		dx := length / float64(n)
+		k := 2.0 * math.Pi * 2.0 / length
+		data := make([]float64, n)
+		for i := range data {
+			data[i] = math.Sin(k * float64(i) * dx)
+		}
+
+		for order, expect := range []func(x float64) float64{
+			func(x float64) float64 { return math.Sin(k * x) },
+			func(x float64) float64 { return k * math.Cos(k*x) },
+			func(x float64) float64 { return -k * k * math.Sin(k*x) },
+			func(x float64) float64 { return -k * k * k * math.Cos(k*x) },
+		} {
+			deriv := Derivative1(data, dx, order)
+			for i := range deriv {
+				if math.Abs(deriv[i]-expect(float64(i)*dx)) > 1e-8 {
+					t.Errorf("n=%d order=%d: Expected %f got %f", n, order, expect(float64(i)*dx), deriv[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestDerivative1Nyquist(t *testing.T) {
+	dx := 0.5
+	data := []float64{1.0, -1.0, 1.0, -1.0, 1.0, -1.0}
+	first := Derivative1(data, dx, 1)
+	if !floats.EqualApprox(first, make([]float64, len(data)), 1e-10) {
+		t.Errorf("First derivative of the Nyquist mode should vanish. Got %v", first)
+	}
+
+	second := Derivative1(data, dx, 2)
+	k := math.Pi / dx
+	for i := range second {
+		if math.Abs(second[i]+k*k*data[i]) > 1e-10 {
+			t.Errorf("Expected %f got %f", -k*k*data[i], second[i])
+		}
+	}
+}
+
+func TestGradientDivergenceLaplacian2(t *testing.T) {
+	nr, nc := 8, 10
+	spacing := [2]float64{0.5, 0.2}
+	ky := 2.0 * math.Pi / (float64(nr) * spacing[0])
+	kx := 2.0 * 2.0 * math.Pi / (float64(nc) * spacing[1])
+
+	data := make([]float64, nr*nc)
+	dy := make([]float64, nr*nc)
+	dx := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := float64(i) * spacing[0]
+			x := float64(j) * spacing[1]
+			data[i*nc+j] = math.Sin(ky*y) * math.Cos(kx*x)
+			dy[i*nc+j] = ky * math.Cos(ky*y) * math.Cos(kx*x)
+			dx[i*nc+j] = -kx * math.Sin(ky*y) * math.Sin(kx*x)
+		}
+	}
+
+	grad := Gradient2(data, nr, nc, spacing)
+	if !floats.EqualApprox(grad[0], dy, 1e-10) || !floats.EqualApprox(grad[1], dx, 1e-10) {
+		t.Errorf("Unexpected gradient")
+	}
+
+	lap := Laplacian2(data, nr, nc, spacing)
+	div := Divergence2(grad, nr, nc, spacing)
+	expect := make([]float64, len(data))
+	floats.ScaleTo(expect, -(kx*kx + ky*ky), data)
+	if !floats.EqualApprox(lap, expect, 1e-9) {
+		t.Errorf("Unexpected Laplacian")
+	}
+	if !floats.EqualApprox(div, expect, 1e-9) {
+		t.Errorf("Divergence of the gradient does not match the Laplacian")
+	}
+
+	mixed := Derivative2(data, nr, nc, spacing, [2]int{1, 1})
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := float64(i) * spacing[0]
+			x := float64(j) * spacing[1]
+			v := -ky * kx * math.Cos(ky*y) * math.Sin(kx*x)
+			if math.Abs(mixed[i*nc+j]-v) > 1e-9 {
+				t.Errorf("Mixed derivative: Expected %f got %f", v, mixed[i*nc+j])
+			}
+		}
+	}
+}
+
+func TestGradientDivergenceLaplacian3(t *testing.T) {
+	nr, nc, nd := 4, 6, 8
+	spacing := [3]float64{1.0, 0.5, 0.25}
+	k := [3]float64{
+		2.0 * math.Pi / (float64(nr) * spacing[0]),
+		2.0 * math.Pi / (float64(nc) * spacing[1]),
+		2.0 * 2.0 * math.Pi / (float64(nd) * spacing[2]),
+	}
+	data := NewMat3(nr, nc, nd, nil)
+	var expectGrad [3]*Mat3
+	for i := range expectGrad {
+		expectGrad[i] = NewMat3(nr, nc, nd, nil)
+	}
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for l := 0; l < nd; l++ {
+				a := k[0] * float64(i) * spacing[0]
+				b := k[1] * float64(j) * spacing[1]
+				c := k[2] * float64(l) * spacing[2]
+				data.Set(i, j, l, math.Sin(a)*math.Sin(b)*math.Cos(c))
+				expectGrad[0].Set(i, j, l, k[0]*math.Cos(a)*math.Sin(b)*math.Cos(c))
+				expectGrad[1].Set(i, j, l, k[1]*math.Sin(a)*math.Cos(b)*math.Cos(c))
+				expectGrad[2].Set(i, j, l, -k[2]*math.Sin(a)*math.Sin(b)*math.Sin(c))
+			}
+		}
+	}
+
+	grad := Gradient3(data, spacing)
+	for i := range grad {
+		if !floats.EqualApprox(grad[i].Data, expectGrad[i].Data, 1e-9) {
+			t.Errorf("Unexpected gradient component %d", i)
+		}
+	}
+
+	k2 := k[0]*k[0] + k[1]*k[1] + k[2]*k[2]
+	expect := make([]float64, len(data.Data))
+	floats.ScaleTo(expect, -k2, data.Data)
+	if lap := Laplacian3(data, spacing); !floats.EqualApprox(lap.Data, expect, 1e-9) {
+		t.Errorf("Unexpected Laplacian")
+	}
+	if div := Divergence3(grad, spacing); !floats.EqualApprox(div.Data, expect, 1e-9) {
+		t.Errorf("Divergence of the gradient does not match the Laplacian")
+	}
+}
+
+func TestDerivativeNegativeOrder(t *testing.T) {
+	for i, fn := range []func(){
+		func() { Derivative1(make([]float64, 8), 1.0, -1) },
+		func() { Derivative2(make([]float64, 16), 4, 4, [2]float64{1.0, 1.0}, [2]int{0, -3}) },
+		func() { Derivative3(NewMat3(2, 2, 2, nil), [3]float64{1.0, 1.0, 1.0}, [3]int{-2, 0, 0}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Test #%d: Expected a panic for a negative order", i)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,655 @@
+package sfft
+
+import (
+	"math/cmplx"
+	"sync"
+)
+
+// TrigType specifies the type of a discrete cosine or sine transform
+type TrigType int
+
+// The transforms are unnormalized and follow the conventions of FFTW (REDFT and RODFT).
+// For an input x of length N, the output X is given by (sums run over n = 0, ..., N-1
+// unless stated otherwise)
+//
+//	DCT1: X_k = x_0 + (-1)^k x_{N-1} + 2 sum_{n=1}^{N-2} x_n cos(pi n k/(N-1))
+//	DCT2: X_k = 2 sum x_n cos(pi (n+1/2) k/N)
+//	DCT3: X_k = x_0 + 2 sum_{n=1}^{N-1} x_n cos(pi n (k+1/2)/N)
+//	DCT4: X_k = 2 sum x_n cos(pi (n+1/2) (k+1/2)/N)
+//	DST1: X_k = 2 sum x_n sin(pi (n+1) (k+1)/(N+1))
+//	DST2: X_k = 2 sum x_n sin(pi (n+1/2) (k+1)/N)
+//	DST3: X_k = (-1)^k x_{N-1} + 2 sum_{n=0}^{N-2} x_n sin(pi (n+1) (k+1/2)/N)
+//	DST4: X_k = 2 sum x_n sin(pi (n+1/2) (k+1/2)/N)
+//
+// DCT1 corresponds to an even extension around both x_0 and x_{N-1} (Neumann boundary
+// conditions on the grid points), and DST1 to an odd extension around the points
+// x_{-1} and x_N (Dirichlet boundary conditions).
+const (
+	DCT1 TrigType = iota
+	DCT2
+	DCT3
+	DCT4
+	DST1
+	DST2
+	DST3
+	DST4
+)
+
+// inverse returns the type of the transform that inverts the transform (up to a factor)
+func (t TrigType) inverse() TrigType {
+	switch t {
+	case DCT2:
+		return DCT3
+	case DCT3:
+		return DCT2
+	case DST2:
+		return DST3
+	case DST3:
+		return DST2
+	default:
+		return t
+	}
+}
+
+// logicalSize returns the length of the equivalent real-input DFT for a transform of
+// length n. The inverse transform is normalized by this factor
+func (t TrigType) logicalSize(n int) int {
+	switch t {
+	case DCT1:
+		return 2 * (n - 1)
+	case DST1:
+		return 2 * (n + 1)
+	default:
+		return 2 * n
+	}
+}
+
+// fftSize returns the length of the complex FFT used to compute a transform of length n.
+// The inverse type of a transform has the same size, such that the same FFT can be used
+// for both
+func (t TrigType) fftSize(n int) int {
+	switch t {
+	case DCT1, DST1:
+		return t.logicalSize(n)
+	case DCT4, DST4:
+		if n%2 == 0 {
+			return n / 2
+		}
+		return 2 * n
+	default:
+		return n
+	}
+}
+
+// DTT1 performs one dimensional discrete cosine and sine transforms. Types I are
+// computed by embedding the sequence with the appropriate symmetry in a sequence of
+// length M (M = 2(N-1) for DCT1 and M = 2(N+1) for DST1) and computing the complex FFT
+// of the embedded sequence. Types II and III are computed with a complex FFT of length N
+// combined with a permutation of the input and a multiplication by twiddle factors
+// (Makhoul's algorithm), and type IV with a complex FFT of length N/2 (or 2N for odd N).
+// The sine transforms are obtained from the cosine transforms by reversing the order of
+// the input or the output and flipping the sign of every other element. A DTT1 uses
+// internal buffers and can not be used from multiple goroutines at the same time.
+type DTT1 struct {
+	kind TrigType
+	n    int
+	ft   CmplxTransform
+	work []complex128
+	buf  []float64
+
+	// twiddle holds the twiddle factors of types II, III and IV. For type IV twiddle is
+	// applied before the FFT and post after the FFT
+	twiddle []complex128
+	post    []complex128
+}
+
+// NewDTT1 returns a new transform of the given type for sequences of length n
+func NewDTT1(n int, kind TrigType) *DTT1 {
+	if kind < DCT1 || kind > DST4 {
+		panic("dtt: Unknown transform type")
+	}
+	if (kind == DCT1 && n < 2) || n < 1 {
+		panic("dtt: Sequence too short")
+	}
+	size := kind.fftSize(n)
+	p := getPlan(size, defaultBackend)
+	d := &DTT1{
+		kind: kind,
+		n:    n,
+		ft:   p.CmplxTransform,
+		work: make([]complex128, size),
+		buf:  make([]float64, n),
+	}
+	switch kind {
+	case DCT2, DCT3, DST2, DST3:
+		// exp(-i pi k/(2N))
+		d.twiddle = make([]complex128, n)
+		for k := range d.twiddle {
+			d.twiddle[k] = unitRoot(k, 4*n)
+		}
+	case DCT4, DST4:
+		if n%2 == 0 {
+			// exp(-i pi (4k+1)/(4N)) and exp(-i pi k/N)
+			d.twiddle = make([]complex128, n/2)
+			d.post = make([]complex128, n/2)
+			for k := range d.twiddle {
+				d.twiddle[k] = unitRoot(4*k+1, 8*n)
+				d.post[k] = unitRoot(k, 2*n)
+			}
+		} else {
+			// exp(-i pi k/(2N)) and exp(-i pi (2k+1)/(4N))
+			d.twiddle = make([]complex128, n)
+			d.post = make([]complex128, n)
+			for k := range d.twiddle {
+				d.twiddle[k] = unitRoot(k, 4*n)
+				d.post[k] = unitRoot(2*k+1, 8*n)
+			}
+		}
+	}
+	return d
+}
+
+// Len returns the length of the sequences
+func (d *DTT1) Len() int {
+	return d.n
+}
+
+// Kind returns the type of the transform
+func (d *DTT1) Kind() TrigType {
+	return d.kind
+}
+
+// Transform performs the forward transform in-place. The length of data has to match the
+// length passed on initialization
+func (d *DTT1) Transform(data []float64) []float64 {
+	return d.apply(data, d.kind)
+}
+
+// Inverse performs the inverse transform in-place, such that Inverse(Transform(x)) = x
+func (d *DTT1) Inverse(data []float64) []float64 {
+	d.apply(data, d.kind.inverse())
+	scale := 1.0 / float64(d.kind.logicalSize(d.n))
+	for i := range data {
+		data[i] *= scale
+	}
+	return data
+}
+
+// apply performs a transform of the given type in-place
+func (d *DTT1) apply(data []float64, kind TrigType) []float64 {
+	if len(data) != d.n {
+		panic("dtt: Inconsistent length of data")
+	}
+	switch kind {
+	case DCT1, DST1:
+		d.embedded(data, kind)
+	case DCT2:
+		d.dct2(data)
+	case DCT3:
+		d.dct3(data)
+	case DCT4:
+		d.dct4(data)
+	case DST2:
+		alternateSign(data)
+		d.dct2(data)
+		reverse(data)
+	case DST3:
+		reverse(data)
+		d.dct3(data)
+		alternateSign(data)
+	case DST4:
+		reverse(data)
+		d.dct4(data)
+		alternateSign(data)
+	}
+	return data
+}
+
+// reverse reverses the order of data
+func reverse(data []float64) {
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+}
+
+// alternateSign flips the sign of the elements with odd index
+func alternateSign(data []float64) {
+	for i := 1; i < len(data); i += 2 {
+		data[i] = -data[i]
+	}
+}
+
+// embedded computes the transforms of type I from the FFT of the symmetric extension
+func (d *DTT1) embedded(data []float64, kind TrigType) {
+	m := len(d.work)
+	work := d.work
+	for i := range work {
+		work[i] = 0.0
+	}
+
+	// Place the input at the given position of the embedded sequence together with
+	// its even (sign = 1) or odd (sign = -1) mirror image
+	put := func(pos int, v, sign float64) {
+		work[pos] += complex(v, 0.0)
+		if mirror := (m - pos) % m; mirror != pos {
+			work[mirror] += complex(sign*v, 0.0)
+		}
+	}
+
+	if kind == DCT1 {
+		for i, v := range data {
+			put(i, v, 1.0)
+		}
+		d.ft.Coefficients(work, work)
+		for k := range data {
+			data[k] = real(work[k])
+		}
+		return
+	}
+	for i, v := range data {
+		put(i+1, v, -1.0)
+	}
+	d.ft.Coefficients(work, work)
+	for k := range data {
+		data[k] = -imag(work[k+1])
+	}
+}
+
+// dct2 computes the DCT2 by transforming the sequence with the even elements in
+// increasing order followed by the odd elements in decreasing order
+func (d *DTT1) dct2(data []float64) {
+	n := d.n
+	for i, v := range data {
+		if i%2 == 0 {
+			d.work[i/2] = complex(v, 0.0)
+		} else {
+			d.work[n-1-i/2] = complex(v, 0.0)
+		}
+	}
+	d.ft.Coefficients(d.work, d.work)
+	for k := range data {
+		data[k] = 2.0 * real(d.twiddle[k]*d.work[k])
+	}
+}
+
+// dct3 computes the DCT3 by reversing the steps of dct2. The inverse FFT of the
+// Hermitian sequence (x_k - i x_(N-k)) exp(i pi k/(2N)) is real and holds the even
+// outputs in increasing order followed by the odd outputs in decreasing order
+func (d *DTT1) dct3(data []float64) {
+	n := d.n
+	d.work[0] = complex(data[0], 0.0)
+	for k := 1; k < n; k++ {
+		d.work[k] = complex(data[k], -data[n-k]) * cmplx.Conj(d.twiddle[k])
+	}
+	d.ft.Sequence(d.work, d.work)
+	for i := range data {
+		if i%2 == 0 {
+			data[i] = real(d.work[i/2])
+		} else {
+			data[i] = real(d.work[n-1-i/2])
+		}
+	}
+}
+
+// dct4 computes the DCT4. For even N, the pairs x_(2n) + i x_(N-1-2n) are transformed
+// with a complex FFT of length N/2. For odd N, the sequence is modulated and transformed
+// with a zero-padded FFT of length 2N
+func (d *DTT1) dct4(data []float64) {
+	n := d.n
+	if n%2 == 1 {
+		for i := range d.work {
+			d.work[i] = 0.0
+		}
+		for i, v := range data {
+			d.work[i] = complex(v, 0.0) * d.twiddle[i]
+		}
+		d.ft.Coefficients(d.work, d.work)
+		for k := range data {
+			data[k] = 2.0 * real(d.post[k]*d.work[k])
+		}
+		return
+	}
+
+	h := n / 2
+	for i := 0; i < h; i++ {
+		d.work[i] = complex(data[2*i], data[n-1-2*i]) * d.twiddle[i]
+	}
+	d.ft.Coefficients(d.work, d.work)
+	for k := 0; k < h; k++ {
+		y := d.work[k] * d.post[k]
+		data[2*k] = 2.0 * real(y)
+		data[n-1-2*k] = -2.0 * imag(y)
+	}
+}
+
+// trigKinds returns the transform type along each of the dim axes. If a single type is
+// given, it is used along all axes
+func trigKinds(dim int, kinds []TrigType) []TrigType {
+	if len(kinds) == 1 {
+		res := make([]TrigType, dim)
+		for i := range res {
+			res[i] = kinds[0]
+		}
+		return res
+	}
+	if len(kinds) != dim {
+		panic("dtt: The number of transform types has to be one or match the number of dimensions")
+	}
+	return kinds
+}
+
+// lineTransform returns the forward or the inverse transform of d
+func lineTransform(d *DTT1, inverse bool) func(data []float64) []float64 {
+	if inverse {
+		return d.Inverse
+	}
+	return d.Transform
+}
+
+// transformStrided applies op to the sequence of length n starting at start with the
+// given stride. buf has to have length n
+func transformStrided(data, buf []float64, start, stride int, op func(data []float64) []float64) {
+	for i := range buf {
+		buf[i] = data[start+i*stride]
+	}
+	op(buf)
+	for i, v := range buf {
+		data[start+i*stride] = v
+	}
+}
+
+// DTT2 performs separable two dimensional cosine and sine transforms of real data stored
+// row-major (e.g. A(i, j) = data[i*nc + j]). The type of the transform can be chosen
+// independently along the rows (axis 0) and the columns (axis 1).
+type DTT2 struct {
+	dttRow *DTT1
+	dttCol *DTT1
+	nr     int
+	nc     int
+	rows   []int
+	cols   []int
+	buf    []float64
+}
+
+// NewDTT2 returns a new 2D transform for an array with nr rows and nc columns. If a
+// single type is given, it is used along both axes. Otherwise, kinds[0] is the transform
+// type along the rows (e.g. the transform of each column) and kinds[1] is the type along
+// the columns (e.g. the transform of each row)
+func NewDTT2(nr, nc int, kinds ...TrigType) *DTT2 {
+	k := trigKinds(2, kinds)
+	rows := make([]int, nr)
+	cols := make([]int, nc)
+	for i := range rows {
+		rows[i] = i
+	}
+	for i := range cols {
+		cols[i] = i
+	}
+	return &DTT2{
+		dttRow: NewDTT1(nc, k[1]),
+		dttCol: NewDTT1(nr, k[0]),
+		nr:     nr,
+		nc:     nc,
+		rows:   rows,
+		cols:   cols,
+		buf:    make([]float64, nr),
+	}
+}
+
+// RowTransform performs an in-place transform of each row
+func (d *DTT2) RowTransform(data []float64, inverse bool) []float64 {
+	op := lineTransform(d.dttRow, inverse)
+	for _, r := range d.rows {
+		op(data[r*d.nc : (r+1)*d.nc])
+	}
+	return data
+}
+
+// ColTransform performs an in-place transform of each column
+func (d *DTT2) ColTransform(data []float64, inverse bool) []float64 {
+	op := lineTransform(d.dttCol, inverse)
+	for _, c := range d.cols {
+		transformStrided(data, d.buf, c, d.nc, op)
+	}
+	return data
+}
+
+// Transform performs the forward transform in-place. The length of data has to be nr*nc
+func (d *DTT2) Transform(data []float64) []float64 {
+	if len(data) != d.nr*d.nc {
+		panic("dtt: Inconsistent size in 2D transform")
+	}
+	d.RowTransform(data, false)
+	return d.ColTransform(data, false)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DTT2) Inverse(data []float64) []float64 {
+	if len(data) != d.nr*d.nc {
+		panic("dtt: Inconsistent size in 2D transform")
+	}
+	d.RowTransform(data, true)
+	return d.ColTransform(data, true)
+}
+
+// DTT3 performs separable three dimensional cosine and sine transforms of real data
+// stored in the same way as Mat3. The type of the transform can be chosen independently
+// along the rows (axis 0), the columns (axis 1) and the depth (axis 2).
+type DTT3 struct {
+	dttRow   *DTT1
+	dttCol   *DTT1
+	dttDepth *DTT1
+	nr       int
+	nc       int
+	nd       int
+	rows     []int
+	cols     []int
+	buf      []float64
+}
+
+// NewDTT3 returns a new 3D transform for an array with nr rows, nc columns and depth nd.
+// If a single type is given, it is used along all axes. Otherwise, kinds[0], kinds[1]
+// and kinds[2] is the transform type along the rows, the columns and the depth,
+// respectively (see NewDTT2)
+func NewDTT3(nr, nc, nd int, kinds ...TrigType) *DTT3 {
+	k := trigKinds(3, kinds)
+	rows := make([]int, nr)
+	cols := make([]int, nc)
+	for i := range rows {
+		rows[i] = i
+	}
+	for i := range cols {
+		cols[i] = i
+	}
+	maxLen := nr
+	if nd > maxLen {
+		maxLen = nd
+	}
+	return &DTT3{
+		dttRow:   NewDTT1(nc, k[1]),
+		dttCol:   NewDTT1(nr, k[0]),
+		dttDepth: NewDTT1(nd, k[2]),
+		nr:       nr,
+		nc:       nc,
+		nd:       nd,
+		rows:     rows,
+		cols:     cols,
+		buf:      make([]float64, maxLen),
+	}
+}
+
+// RowTransform performs an in-place transform of each row
+func (d *DTT3) RowTransform(data []float64, inverse bool) []float64 {
+	op := lineTransform(d.dttRow, inverse)
+	for _, r := range d.rows {
+		for k := 0; k < d.nd; k++ {
+			start := k*d.nr*d.nc + r*d.nc
+			op(data[start : start+d.nc])
+		}
+	}
+	return data
+}
+
+// ColTransform performs an in-place transform of each column
+func (d *DTT3) ColTransform(data []float64, inverse bool) []float64 {
+	op := lineTransform(d.dttCol, inverse)
+	for k := 0; k < d.nd; k++ {
+		for _, c := range d.cols {
+			transformStrided(data, d.buf[:d.nr], k*d.nr*d.nc+c, d.nc, op)
+		}
+	}
+	return data
+}
+
+// DepthTransform performs an in-place transform along the depth
+func (d *DTT3) DepthTransform(data []float64, inverse bool) []float64 {
+	op := lineTransform(d.dttDepth, inverse)
+	for _, r := range d.rows {
+		for c := 0; c < d.nc; c++ {
+			transformStrided(data, d.buf[:d.nd], r*d.nc+c, d.nr*d.nc, op)
+		}
+	}
+	return data
+}
+
+// Transform performs the forward transform in-place. The length of data has to be
+// nr*nc*nd
+func (d *DTT3) Transform(data []float64) []float64 {
+	if len(data) != d.nr*d.nc*d.nd {
+		panic("dtt: Inconsistent size in 3D transform")
+	}
+	d.RowTransform(data, false)
+	d.ColTransform(data, false)
+	return d.DepthTransform(data, false)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DTT3) Inverse(data []float64) []float64 {
+	if len(data) != d.nr*d.nc*d.nd {
+		panic("dtt: Inconsistent size in 3D transform")
+	}
+	d.RowTransform(data, true)
+	d.ColTransform(data, true)
+	return d.DepthTransform(data, true)
+}
+
+// DTT2Par is a parallel version of DTT2
+type DTT2Par struct {
+	Transformers []*DTT2
+}
+
+// NewDTT2Par returns a new parallel 2D transform. nWork is the number of workers. As for
+// FFT2Par, both the number of rows and the number of columns has to be divisible by the
+// number of workers. The transform types are specified as for NewDTT2
+func NewDTT2Par(nr, nc, nWork int, kinds ...TrigType) *DTT2Par {
+	if nr%nWork != 0 || nc%nWork != 0 {
+		panic("dtt: The number of rows and columns has to be divisible by the number of workers")
+	}
+	var dtt DTT2Par
+	dtt.Transformers = make([]*DTT2, nWork)
+	for i := 0; i < nWork; i++ {
+		dtt.Transformers[i] = NewDTT2(nr, nc, kinds...)
+
+		// Split rows and cols among the workers
+		rowsPerWorker := nr / nWork
+		colsPerWorker := nc / nWork
+		dtt.Transformers[i].rows = dtt.Transformers[i].rows[i*rowsPerWorker : (i+1)*rowsPerWorker]
+		dtt.Transformers[i].cols = dtt.Transformers[i].cols[i*colsPerWorker : (i+1)*colsPerWorker]
+	}
+	return &dtt
+}
+
+// transform performs the forward or the inverse transform
+func (d *DTT2Par) transform(data []float64, inverse bool) []float64 {
+	if len(data) != d.Transformers[0].nr*d.Transformers[0].nc {
+		panic("dtt: Inconsistent size in 2D transform")
+	}
+	var wg sync.WaitGroup
+	for i := range d.Transformers {
+		wg.Add(1)
+		go func(num int) {
+			defer wg.Done()
+			d.Transformers[num].RowTransform(data, inverse)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range d.Transformers {
+		wg.Add(1)
+		go func(num int) {
+			defer wg.Done()
+			d.Transformers[num].ColTransform(data, inverse)
+		}(i)
+	}
+	wg.Wait()
+	return data
+}
+
+// Transform performs the forward transform in-place
+func (d *DTT2Par) Transform(data []float64) []float64 {
+	return d.transform(data, false)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DTT2Par) Inverse(data []float64) []float64 {
+	return d.transform(data, true)
+}
+
+// DTT3Par is a parallel version of DTT3
+type DTT3Par struct {
+	Transforms []*DTT3
+}
+
+// NewDTT3Par returns a new parallel 3D transform. nWorkers is the number of workers. As
+// for FFT3Par, both the number of rows and the number of columns has to be divisible by
+// the number of workers. The transform types are specified as for NewDTT3
+func NewDTT3Par(nr, nc, nd, nWorkers int, kinds ...TrigType) *DTT3Par {
+	if nr%nWorkers != 0 || nc%nWorkers != 0 {
+		panic("dtt: The number of rows and the number of columns must be divisible by the number of workers")
+	}
+	var dtt DTT3Par
+	dtt.Transforms = make([]*DTT3, nWorkers)
+	for i := 0; i < nWorkers; i++ {
+		dtt.Transforms[i] = NewDTT3(nr, nc, nd, kinds...)
+		rowsPerWorker := nr / nWorkers
+		colsPerWorker := nc / nWorkers
+		dtt.Transforms[i].rows = dtt.Transforms[i].rows[i*rowsPerWorker : (i+1)*rowsPerWorker]
+		dtt.Transforms[i].cols = dtt.Transforms[i].cols[i*colsPerWorker : (i+1)*colsPerWorker]
+	}
+	return &dtt
+}
+
+// transform performs the forward or the inverse transform
+func (d *DTT3Par) transform(data []float64, inverse bool) []float64 {
+	first := d.Transforms[0]
+	if len(data) != first.nr*first.nc*first.nd {
+		panic("dtt: Inconsistent size in 3D transform")
+	}
+	passes := []func(t *DTT3){
+		func(t *DTT3) { t.RowTransform(data, inverse) },
+		func(t *DTT3) { t.ColTransform(data, inverse) },
+		func(t *DTT3) { t.DepthTransform(data, inverse) },
+	}
+	var wg sync.WaitGroup
+	for _, pass := range passes {
+		for i := range d.Transforms {
+			wg.Add(1)
+			go func(num int) {
+				defer wg.Done()
+				pass(d.Transforms[num])
+			}(i)
+		}
+		wg.Wait()
+	}
+	return data
+}
+
+// Transform performs the forward transform in-place
+func (d *DTT3Par) Transform(data []float64) []float64 {
+	return d.transform(data, false)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DTT3Par) Inverse(data []float64) []float64 {
+	return d.transform(data, true)
+}
@@ -0,0 +1,153 @@
+package sfft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// directDTT evaluates the transforms from their definitions
+func directDTT(x []float64, kind TrigType) []float64 {
+	n := len(x)
+	nf := float64(n)
+	res := make([]float64, n)
+	for k := range res {
+		kf := float64(k)
+		for i, v := range x {
+			fi := float64(i)
+			switch kind {
+			case DCT1:
+				if i == 0 || i == n-1 {
+					res[k] += v * math.Cos(math.Pi*fi*kf/(nf-1.0))
+				} else {
+					res[k] += 2.0 * v * math.Cos(math.Pi*fi*kf/(nf-1.0))
+				}
+			case DCT2:
+				res[k] += 2.0 * v * math.Cos(math.Pi*(fi+0.5)*kf/nf)
+			case DCT3:
+				if i == 0 {
+					res[k] += v
+				} else {
+					res[k] += 2.0 * v * math.Cos(math.Pi*fi*(kf+0.5)/nf)
+				}
+			case DCT4:
+				res[k] += 2.0 * v * math.Cos(math.Pi*(fi+0.5)*(kf+0.5)/nf)
+			case DST1:
+				res[k] += 2.0 * v * math.Sin(math.Pi*(fi+1.0)*(kf+1.0)/(nf+1.0))
+			case DST2:
+				res[k] += 2.0 * v * math.Sin(math.Pi*(fi+0.5)*(kf+1.0)/nf)
+			case DST3:
+				if i == n-1 {
+					res[k] += v * math.Sin(math.Pi*(fi+1.0)*(kf+0.5)/nf)
+				} else {
+					res[k] += 2.0 * v * math.Sin(math.Pi*(fi+1.0)*(kf+0.5)/nf)
+				}
+			case DST4:
+				res[k] += 2.0 * v * math.Sin(math.Pi*(fi+0.5)*(kf+0.5)/nf)
+			}
+		}
+	}
+	return res
+}
+
+func randomSeq(rng *rand.Rand, n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	return x
+}
+
+func TestDTT1(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for kind := DCT1; kind <= DST4; kind++ {
+		for _, n := range []int{2, 3, 7, 8} {
+			x := randomSeq(rng, n)
+			dtt := NewDTT1(n, kind)
+			got := dtt.Transform(append([]float64{}, x...))
+			want := directDTT(x, kind)
+			if !floats.EqualApprox(got, want, 1e-10) {
+				t.Errorf("Type %d, n=%d: Expected\n%v\ngot\n%v", kind, n, want, got)
+			}
+			if !floats.EqualApprox(dtt.Inverse(got), x, 1e-10) {
+				t.Errorf("Type %d, n=%d: Inverse does not recover the input", kind, n)
+			}
+		}
+	}
+}
+
+// applyAlong applies the transform along an axis of an array with the given shape in
+// memory order, by extracting each line explicitly
+func applyAlong(data []float64, shape []int, axis int, kind TrigType) []float64 {
+	res := append([]float64{}, data...)
+	stride := 1
+	for i := axis + 1; i < len(shape); i++ {
+		stride *= shape[i]
+	}
+	n := shape[axis]
+	for start := range res {
+		if (start/stride)%n != 0 {
+			continue
+		}
+		line := make([]float64, n)
+		for i := range line {
+			line[i] = data[start+i*stride]
+		}
+		line = directDTT(line, kind)
+		for i, v := range line {
+			res[start+i*stride] = v
+		}
+	}
+	return res
+}
+
+func TestDTT2(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	nr, nc := 6, 4
+	x := randomSeq(rng, nr*nc)
+	for _, kinds := range [][]TrigType{{DCT2}, {DST1, DCT1}, {DCT4, DST3}} {
+		k := trigKinds(2, kinds)
+		want := applyAlong(applyAlong(x, []int{nr, nc}, 0, k[0]), []int{nr, nc}, 1, k[1])
+
+		for i, dtt := range []interface {
+			Transform(data []float64) []float64
+			Inverse(data []float64) []float64
+		}{NewDTT2(nr, nc, kinds...), NewDTT2Par(nr, nc, 2, kinds...)} {
+			got := dtt.Transform(append([]float64{}, x...))
+			if !floats.EqualApprox(got, want, 1e-10) {
+				t.Errorf("Kinds %v, transform #%d: Expected\n%v\ngot\n%v", kinds, i, want, got)
+			}
+			if !floats.EqualApprox(dtt.Inverse(got), x, 1e-10) {
+				t.Errorf("Kinds %v, transform #%d: Inverse does not recover the input", kinds, i)
+			}
+		}
+	}
+}
+
+func TestDTT3(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	nr, nc, nd := 4, 6, 5
+	x := randomSeq(rng, nr*nc*nd)
+	shape := []int{nd, nr, nc}
+	for _, kinds := range [][]TrigType{{DST2}, {DCT1, DST4, DCT3}} {
+		k := trigKinds(3, kinds)
+		want := applyAlong(x, shape, 1, k[0])
+		want = applyAlong(want, shape, 2, k[1])
+		want = applyAlong(want, shape, 0, k[2])
+
+		for i, dtt := range []interface {
+			Transform(data []float64) []float64
+			Inverse(data []float64) []float64
+		}{NewDTT3(nr, nc, nd, kinds...), NewDTT3Par(nr, nc, nd, 2, kinds...)} {
+			got := dtt.Transform(append([]float64{}, x...))
+			if !floats.EqualApprox(got, want, 1e-10) {
+				t.Errorf("Kinds %v, transform #%d: Unexpected result", kinds, i)
+			}
+			if !floats.EqualApprox(dtt.Inverse(got), x, 1e-10) {
+				t.Errorf("Kinds %v, transform #%d: Inverse does not recover the input", kinds, i)
+			}
+		}
+	}
+}
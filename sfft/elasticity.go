@@ -0,0 +1,288 @@
+package sfft
+
+import "math"
+
+// voigt maps a pair of tensor indices to the corresponding index in Voigt notation
+// (xx, yy, zz, yz, xz, xy)
+var voigt = [3][3]int{{0, 5, 4}, {5, 1, 3}, {4, 3, 2}}
+
+// IsotropicStiffness returns the stiffness matrix in Voigt notation of an isotropic
+// material with the given shear modulus and Poisson's ratio
+func IsotropicStiffness(shear, poisson float64) [6][6]float64 {
+	lambda := 2.0 * shear * poisson / (1.0 - 2.0*poisson)
+	return CubicStiffness(lambda+2.0*shear, lambda, shear)
+}
+
+// CubicStiffness returns the stiffness matrix in Voigt notation of a material with cubic
+// symmetry. The crystal axes are aligned with the grid axes
+func CubicStiffness(c11, c12, c44 float64) [6][6]float64 {
+	var c [6][6]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			c[i][j] = c12
+		}
+		c[i][i] = c11
+		c[i+3][i+3] = c44
+	}
+	return c
+}
+
+// invert3 returns the inverse of a 3x3 matrix
+func invert3(m [3][3]float64) [3][3]float64 {
+	var inv [3][3]float64
+	inv[0][0] = m[1][1]*m[2][2] - m[1][2]*m[2][1]
+	inv[0][1] = m[0][2]*m[2][1] - m[0][1]*m[2][2]
+	inv[0][2] = m[0][1]*m[1][2] - m[0][2]*m[1][1]
+	inv[1][0] = m[1][2]*m[2][0] - m[1][0]*m[2][2]
+	inv[1][1] = m[0][0]*m[2][2] - m[0][2]*m[2][0]
+	inv[1][2] = m[0][2]*m[1][0] - m[0][0]*m[1][2]
+	inv[2][0] = m[1][0]*m[2][1] - m[1][1]*m[2][0]
+	inv[2][1] = m[0][1]*m[2][0] - m[0][0]*m[2][1]
+	inv[2][2] = m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	det := m[0][0]*inv[0][0] + m[0][1]*inv[1][0] + m[0][2]*inv[2][0]
+	if det == 0.0 {
+		panic("elasticity: Singular matrix")
+	}
+	for i := range inv {
+		for j := range inv[i] {
+			inv[i][j] /= det
+		}
+	}
+	return inv
+}
+
+// ElasticFields holds the solution of the elasticity problem. The tensors are symmetric,
+// and the (i, j) and (j, i) items point to the same array. Index 0 of the tensors refers
+// to the rows, 1 to the columns and 2 to the depth of the grid.
+type ElasticFields struct {
+	// Strain is the total strain
+	Strain [3][3]*Mat3
+
+	// Stress is the stress
+	Stress [3][3]*Mat3
+
+	// EnergyDensity is the elastic energy density 0.5*stress:(strain - eigenstrain)
+	EnergyDensity *Mat3
+
+	// Energy is the total elastic energy (e.g. the energy density integrated over the
+	// domain)
+	Energy float64
+}
+
+// Elasticity computes the elastic fields arising from eigenstrains in a homogeneous
+// anisotropic medium with periodic boundary conditions, following the microelasticity
+// theory of Khachaturyan. The eigenstrain field is a sum of concentration fields each
+// multiplied by a constant eigenstrain tensor
+//
+//	eps*(r) = sum_p c_p(r) eps0_p
+//
+// In Fourier space the displacement is given by the Green's tensor
+// G(n) = (C_ijkl n_j n_l)^-1, where n is the unit wave vector. The homogeneous strain
+// (the zero mode) equals the mean eigenstrain, which corresponds to a body that is free
+// to relax its shape, such that the mean stress vanishes.
+type Elasticity struct {
+	ft      *FFT3
+	nr      int
+	nc      int
+	nd      int
+	stiff   [3][3][3][3]float64
+	spacing [3]float64
+	normals [][3]float64
+	green   [][3][3]float64
+}
+
+// NewElasticity returns a new elasticity solver for a grid with nr rows, nc columns and
+// depth nd. stiffness is the stiffness matrix in Voigt notation. The grid spacing is 1.0
+// (see SetSpacing)
+func NewElasticity(nr, nc, nd int, stiffness [6][6]float64) *Elasticity {
+	e := &Elasticity{
+		ft: NewFFT3(nr, nc, nd),
+		nr: nr,
+		nc: nc,
+		nd: nd,
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					e.stiff[i][j][k][l] = stiffness[voigt[i][j]][voigt[k][l]]
+				}
+			}
+		}
+	}
+	e.SetSpacing(1.0, 1.0, 1.0)
+	return e
+}
+
+// withoutNyquist returns zero if f is the Nyquist frequency and f otherwise. The sign of
+// the Nyquist frequency is ambiguous, and the corresponding component of the wave vector
+// is set to zero as for first order derivatives (see Derivative3)
+func withoutNyquist(f float64) float64 {
+	if f == 0.5 {
+		return 0.0
+	}
+	return f
+}
+
+// SetSpacing sets the grid spacing along the rows, the columns and the depth
+func (e *Elasticity) SetSpacing(dr, dc, dd float64) {
+	e.spacing = [3]float64{dr, dc, dd}
+	n := e.nr * e.nc * e.nd
+	e.normals = make([][3]float64, n)
+	e.green = make([][3][3]float64, n)
+	forEachFreq3(e.nr, e.nc, e.nd, e.nc, func(idx int, fr, fc, fd float64) {
+		k := [3]float64{withoutNyquist(fr) / dr, withoutNyquist(fc) / dc, withoutNyquist(fd) / dd}
+		length := k[0]*k[0] + k[1]*k[1] + k[2]*k[2]
+		if length == 0.0 {
+			return
+		}
+		for i := range k {
+			e.normals[idx][i] = k[i] / math.Sqrt(length)
+		}
+		e.green[idx] = e.GreenTensor(e.normals[idx])
+	})
+}
+
+// GreenTensor returns the Green's tensor (C_ijkl n_j n_l)^-1 for the unit vector n
+func (e *Elasticity) GreenTensor(n [3]float64) [3][3]float64 {
+	var m [3][3]float64
+	for i := 0; i < 3; i++ {
+		for k := 0; k < 3; k++ {
+			for j := 0; j < 3; j++ {
+				for l := 0; l < 3; l++ {
+					m[i][k] += e.stiff[i][j][k][l] * n[j] * n[l]
+				}
+			}
+		}
+	}
+	return invert3(m)
+}
+
+// Green returns the Green's tensor at the (i, j, k) element of the frequency grid (the
+// same layout as the coefficients of FFT3). The tensor is zero at the zero frequency and
+// where all non-zero frequencies are Nyquist frequencies, in which case no strain is
+// generated by the mode
+func (e *Elasticity) Green(i, j, k int) [3][3]float64 {
+	f := flattened3{nr: e.nr, nc: e.nc, nd: e.nd}
+	return e.green[f.Index(i, j, k)]
+}
+
+// contract returns C_ijkl*eps_kl
+func (e *Elasticity) contract(eps [3][3]float64) [3][3]float64 {
+	var sigma [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					sigma[i][j] += e.stiff[i][j][k][l] * eps[k][l]
+				}
+			}
+		}
+	}
+	return sigma
+}
+
+// Solve computes the strain, stress and elastic energy for the given concentration
+// fields. eigenstrains[p] is the (symmetric) eigenstrain tensor associated with conc[p].
+func (e *Elasticity) Solve(conc []*Mat3, eigenstrains [][3][3]float64) *ElasticFields {
+	if len(conc) != len(eigenstrains) {
+		panic("elasticity: The number of concentration fields has to match the number of eigenstrains")
+	}
+	n := e.nr * e.nc * e.nd
+
+	// Fourier coefficients of the concentrations and the eigenstresses C:eps0
+	coeff := make([][]complex128, len(conc))
+	eigenstress := make([][3][3]float64, len(conc))
+	for p, c := range conc {
+		nr, nc, nd := c.Dims()
+		if nr != e.nr || nc != e.nc || nd != e.nd {
+			panic("elasticity: Inconsistent size of the concentration field")
+		}
+		coeff[p] = ToComplex(c.Data)
+		e.ft.FFT(coeff[p])
+		eigenstress[p] = e.contract(eigenstrains[p])
+	}
+
+	var strain [3][3][]complex128
+	for i := 0; i < 3; i++ {
+		for j := i; j < 3; j++ {
+			strain[i][j] = make([]complex128, n)
+		}
+	}
+	for idx := 0; idx < n; idx++ {
+		if idx == 0 {
+			// Homogeneous strain equals the mean eigenstrain
+			for p := range coeff {
+				for i := 0; i < 3; i++ {
+					for j := i; j < 3; j++ {
+						strain[i][j][0] += coeff[p][0] * complex(eigenstrains[p][i][j], 0.0)
+					}
+				}
+			}
+			continue
+		}
+
+		// Eigenstress projected on the wave vector
+		var sn [3]complex128
+		nv := e.normals[idx]
+		for p := range coeff {
+			for i := 0; i < 3; i++ {
+				proj := 0.0
+				for j := 0; j < 3; j++ {
+					proj += eigenstress[p][i][j] * nv[j]
+				}
+				sn[i] += coeff[p][idx] * complex(proj, 0.0)
+			}
+		}
+
+		var w [3]complex128
+		g := e.green[idx]
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				w[i] += complex(g[i][j], 0.0) * sn[j]
+			}
+		}
+		for i := 0; i < 3; i++ {
+			for j := i; j < 3; j++ {
+				strain[i][j][idx] = 0.5 * (complex(nv[j], 0.0)*w[i] + complex(nv[i], 0.0)*w[j])
+			}
+		}
+	}
+
+	res := &ElasticFields{EnergyDensity: NewMat3(e.nr, e.nc, e.nd, nil)}
+	for i := 0; i < 3; i++ {
+		for j := i; j < 3; j++ {
+			e.ft.IFFT(strain[i][j])
+			res.Strain[i][j] = NewMat3(e.nr, e.nc, e.nd, realScaled(strain[i][j], n))
+			res.Strain[j][i] = res.Strain[i][j]
+			res.Stress[i][j] = NewMat3(e.nr, e.nc, e.nd, nil)
+			res.Stress[j][i] = res.Stress[i][j]
+		}
+	}
+
+	volume := e.spacing[0] * e.spacing[1] * e.spacing[2]
+	for idx := 0; idx < n; idx++ {
+		var elastic [3][3]float64
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				elastic[i][j] = res.Strain[i][j].Data[idx]
+				for p, c := range conc {
+					elastic[i][j] -= c.Data[idx] * eigenstrains[p][i][j]
+				}
+			}
+		}
+		sigma := e.contract(elastic)
+		density := 0.0
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				density += 0.5 * sigma[i][j] * elastic[i][j]
+			}
+			for j := i; j < 3; j++ {
+				res.Stress[i][j].Data[idx] = sigma[i][j]
+			}
+		}
+		res.EnergyDensity.Data[idx] = density
+		res.Energy += density * volume
+	}
+	return res
+}
@@ -0,0 +1,136 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// sphere returns a field that is 1 inside a sphere with the given radius centered in
+// the middle of the grid and 0 outside
+func sphere(nr, nc, nd int, radius float64) *Mat3 {
+	c := NewMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				x := float64(i - nr/2)
+				y := float64(j - nc/2)
+				z := float64(k - nd/2)
+				if x*x+y*y+z*z < radius*radius {
+					c.Set(i, j, k, 1.0)
+				}
+			}
+		}
+	}
+	return c
+}
+
+func TestStiffness(t *testing.T) {
+	shear, poisson := 30.0, 0.25
+	iso := IsotropicStiffness(shear, poisson)
+	cubic := CubicStiffness(iso[0][0], iso[0][1], 0.5*(iso[0][0]-iso[0][1]))
+	if iso != cubic {
+		t.Errorf("Expected an isotropic cubic stiffness to match\n%v\ngot\n%v", iso, cubic)
+	}
+	if math.Abs(iso[3][3]-shear) > 1e-12 || math.Abs(iso[0][0]-3.0*shear) > 1e-12 {
+		t.Errorf("Unexpected stiffness %v", iso)
+	}
+}
+
+func TestGreenTensorInverse(t *testing.T) {
+	e := NewElasticity(4, 4, 4, CubicStiffness(200.0, 130.0, 100.0))
+	n := [3]float64{1.0 / math.Sqrt(6.0), 2.0 / math.Sqrt(6.0), -1.0 / math.Sqrt(6.0)}
+	g := e.GreenTensor(n)
+	for i := 0; i < 3; i++ {
+		for m := 0; m < 3; m++ {
+			v := 0.0
+			for k := 0; k < 3; k++ {
+				for j := 0; j < 3; j++ {
+					for l := 0; l < 3; l++ {
+						v += e.stiff[i][j][k][l] * n[j] * n[l] * g[k][m]
+					}
+				}
+			}
+			expect := 0.0
+			if i == m {
+				expect = 1.0
+			}
+			if math.Abs(v-expect) > 1e-12 {
+				t.Errorf("(%d, %d): Expected %f got %f", i, m, expect, v)
+			}
+		}
+	}
+}
+
+func TestSphericalInclusionEnergy(t *testing.T) {
+	// For a dilatational eigenstrain in an isotropic medium the energy is independent of
+	// the shape of the inclusion, E = 2*mu*(1 + nu)/(1 - nu)*eps0^2*V_incl*(1 - f), where
+	// f is the volume fraction of the inclusion. An odd grid size is used, since modes at
+	// the Nyquist frequency can not relax on an even grid
+	n := 15
+	spacing := 0.5
+	shear, poisson := 50.0, 0.3
+	eps0 := 0.01
+	e := NewElasticity(n, n, n, IsotropicStiffness(shear, poisson))
+	e.SetSpacing(spacing, spacing, spacing)
+
+	c := sphere(n, n, n, 4.0)
+	eigen := [3][3]float64{{eps0, 0.0, 0.0}, {0.0, eps0, 0.0}, {0.0, 0.0, eps0}}
+	res := e.Solve([]*Mat3{c}, [][3][3]float64{eigen})
+
+	f := floats.Sum(c.Data) / float64(len(c.Data))
+	volIncl := floats.Sum(c.Data) * math.Pow(spacing, 3)
+	expect := 2.0 * shear * (1.0 + poisson) / (1.0 - poisson) * eps0 * eps0 * volIncl * (1.0 - f)
+	if math.Abs(res.Energy-expect) > 1e-10*expect {
+		t.Errorf("Expected energy %e got %e", expect, res.Energy)
+	}
+
+	// The mean stress vanishes and the mean strain equals the mean eigenstrain
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			meanStress := floats.Sum(res.Stress[i][j].Data) / float64(len(c.Data))
+			meanStrain := floats.Sum(res.Strain[i][j].Data) / float64(len(c.Data))
+			if math.Abs(meanStress) > 1e-10 || math.Abs(meanStrain-f*eigen[i][j]) > 1e-12 {
+				t.Errorf("(%d, %d): Unexpected mean stress %e or strain %e", i, j, meanStress, meanStrain)
+			}
+		}
+	}
+}
+
+func TestElasticEquilibrium(t *testing.T) {
+	n := 16
+	e := NewElasticity(n, n, n, CubicStiffness(200.0, 130.0, 100.0))
+	c1 := NewMat3(n, n, n, nil)
+	c2 := NewMat3(n, n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			for k := 0; k < n; k++ {
+				x := float64(i - n/2)
+				y := float64(j - n/2)
+				z := float64(k - n/2)
+				c1.Set(i, j, k, math.Exp(-(x*x+y*y+z*z)/8.0))
+				c2.Set(i, j, k, math.Exp(-((x-3.0)*(x-3.0)+y*y+(z+2.0)*(z+2.0))/4.0))
+			}
+		}
+	}
+	eigen := [][3][3]float64{
+		{{0.02, 0.0, 0.0}, {0.0, -0.01, 0.0}, {0.0, 0.0, -0.01}},
+		{{0.01, 0.005, 0.0}, {0.005, 0.01, 0.0}, {0.0, 0.0, 0.0}},
+	}
+	res := e.Solve([]*Mat3{c1, c2}, eigen)
+
+	// The divergence of the stress vanishes
+	spacing := [3]float64{1.0, 1.0, 1.0}
+	for i := 0; i < 3; i++ {
+		div := Divergence3(res.Stress[i], spacing)
+		if floats.Norm(div.Data, math.Inf(1)) > 1e-10 {
+			t.Errorf("Row %d: Stress is not divergence free. Max %e", i, floats.Norm(div.Data, math.Inf(1)))
+		}
+	}
+
+	// The energy is the integral of the energy density and is positive
+	if res.Energy <= 0.0 || math.Abs(res.Energy-floats.Sum(res.EnergyDensity.Data)) > 1e-12*res.Energy {
+		t.Errorf("Unexpected energy %e", res.Energy)
+	}
+}
@@ -1,25 +1,29 @@
 // Package sfft provides a set of method to simplify calculations of 2D and 3D
-// FFTs. It is solely based on the 1D FFT method provided by Gonum.
+// FFTs. The multidimensional transforms are built from 1D FFTs provided by a
+// pluggable backend. By default, the 1D FFTs from Gonum are used, and a native
+// mixed radix implementation is available as well (see Backend and RegisterBackend).
 package sfft
 
-import (
-	"gonum.org/v1/gonum/dsp/fourier"
-)
-
 // GonumFT is a type definition of Gonum's Coefficients and Sequence
 type GonumFT func(dst []complex128, data []complex128) []complex128
 
 // FFT1 is a data type for 1D FFTs
 type FFT1 struct {
-	ft *fourier.FFT
+	ft RealTransform
 	n  int
 }
 
 // NewFFT1 creates a new type for FFT1. Size is the length of the array that will be
 // Fourier Transformed
 func NewFFT1(size int) *FFT1 {
+	return NewFFT1With(size, defaultBackend)
+}
+
+// NewFFT1With returns a new FFT1 that uses the backend registered with the given name
+// (see RegisterBackend)
+func NewFFT1With(size int, backend string) *FFT1 {
 	return &FFT1{
-		ft: fourier.NewFFT(size),
+		ft: getRealPlan(size, backend).RealTransform,
 		n:  size,
 	}
 }
@@ -51,10 +55,53 @@ func (f *FFT1) Freq(i int) float64 {
 	return freq
 }
 
+// CFFT is a data type for 1D FFTs of complex sequences
+type CFFT struct {
+	ft CmplxTransform
+}
+
+// NewCFFT returns a new CFFT. Size is the length of the sequences that will be
+// Fourier Transformed
+func NewCFFT(size int) *CFFT {
+	return NewCFFTWith(size, defaultBackend)
+}
+
+// NewCFFTWith returns a new CFFT that uses the given backend
+func NewCFFTWith(size int, backend string) *CFFT {
+	p := getPlan(size, backend)
+	return &CFFT{
+		ft: p.CmplxTransform,
+	}
+}
+
+// FFT performs in-place forward FFT. The length of data has to match the size
+// passed when the type was initialized
+func (f *CFFT) FFT(data []complex128) []complex128 {
+	return f.ft.Coefficients(data, data)
+}
+
+// IFFT performs in-place inverse FFT. The transform is unnormalized, such that
+// a forward transform followed by an inverse transform multiplies the data by
+// the length of the sequence
+func (f *CFFT) IFFT(coeff []complex128) []complex128 {
+	return f.ft.Sequence(coeff, coeff)
+}
+
+// Freq return the frequency corresponding to the coefficient at index i. The spacing
+// is assumed to be 1.0
+func (f *CFFT) Freq(i int) float64 {
+	n := f.ft.Len()
+	freq := float64(i) / float64(n)
+	if i > n/2 {
+		freq = freq - 1.0
+	}
+	return freq
+}
+
 // FFT2 is a data type for two dimensional Fourier Transforms
 type FFT2 struct {
-	ftRow *fourier.CmplxFFT
-	ftCol *fourier.CmplxFFT
+	ftRow CmplxTransform
+	ftCol CmplxTransform
 	nr    int
 	nc    int
 	cols  []int
@@ -63,6 +110,11 @@ type FFT2 struct {
 
 // NewFFT2 return a new FFT2. nr is the number of rows, and nc is the number of columns
 func NewFFT2(nr, nc int) *FFT2 {
+	return NewFFT2With(nr, nc, defaultBackend)
+}
+
+// NewFFT2With returns a new FFT2 that uses the given backend
+func NewFFT2With(nr, nc int, backend string) *FFT2 {
 	cols := make([]int, nc)
 	rows := make([]int, nr)
 	for i := 0; i < nc; i++ {
@@ -71,9 +123,10 @@ func NewFFT2(nr, nc int) *FFT2 {
 	for i := 0; i < nr; i++ {
 		rows[i] = i
 	}
+	ps := sharedPlans(backend, nc, nr)
 	return &FFT2{
-		ftRow: fourier.NewCmplxFFT(nc),
-		ftCol: fourier.NewCmplxFFT(nr),
+		ftRow: ps[0].CmplxTransform,
+		ftCol: ps[1].CmplxTransform,
 		nr:    nr,
 		nc:    nc,
 		cols:  cols,
@@ -147,17 +200,27 @@ func (f *FFT2) Freq(i int) []float64 {
 
 // FFT3 is a structure for performing 3D FFTs
 type FFT3 struct {
-	row    *fourier.CmplxFFT
-	col    *fourier.CmplxFFT
-	depth  *fourier.CmplxFFT
+	row    CmplxTransform
+	col    CmplxTransform
+	depth  CmplxTransform
 	rows   []int
 	cols   []int
 	planes []int
+
+	// batch is the number of depth lines that are transformed together and block holds
+	// the gathered lines
+	batch int
+	block []complex128
 }
 
 // NewFFT3 returns a new 3D Fourier transform object. nr is the number of rows,
 // nc is the number of columns and nd is the number of nr x nc "sheets"
 func NewFFT3(nr, nc, nd int) *FFT3 {
+	return NewFFT3With(nr, nc, nd, defaultBackend)
+}
+
+// NewFFT3With returns a new FFT3 that uses the given backend
+func NewFFT3With(nr, nc, nd int, backend string) *FFT3 {
 	rows := make([]int, nr)
 	cols := make([]int, nc)
 	for i := 0; i < nr; i++ {
@@ -166,15 +229,32 @@ func NewFFT3(nr, nc, nd int) *FFT3 {
 	for i := 0; i < nc; i++ {
 		cols[i] = i
 	}
+	ps := sharedPlans(backend, nc, nr, nd)
 	return &FFT3{
-		row:   fourier.NewCmplxFFT(nc),
-		col:   fourier.NewCmplxFFT(nr),
-		depth: fourier.NewCmplxFFT(nd),
+		row:   ps[0].CmplxTransform,
+		col:   ps[1].CmplxTransform,
+		depth: ps[2].CmplxTransform,
 		rows:  rows,
 		cols:  cols,
+		batch: 1,
 	}
 }
 
+// SetBatchSize sets the number of depth lines that are transformed together by FFT and
+// IFFT. Neighbouring lines along the columns are gathered into a block where the lines
+// are interleaved, transformed, and scattered back. Hence, each plane is accessed with
+// contiguous reads of b elements instead of one element per line, which reduces the
+// number of cache misses for large grids. If the backend implements BatchTransform,
+// the butterflies operate on all lines in the block at once. Otherwise, the lines in
+// the block are transformed one at the time. A batch size of 1 (the default) transforms
+// each line separately.
+func (f *FFT3) SetBatchSize(b int) {
+	if b < 1 {
+		panic("FFT3: The batch size has to be positive")
+	}
+	f.batch = b
+}
+
 // RowTransform performs FFT over rows
 func (f *FFT3) RowTransform(data []complex128, op GonumFT) []complex128 {
 	nc := f.row.Len()
@@ -220,29 +300,100 @@ func (f *FFT3) DepthTransform(data []complex128, op GonumFT) []complex128 {
 	return data
 }
 
+// BatchDepthTransform performs FFT over the depth in blocks of b lines (see
+// SetBatchSize). The forward transform is applied if inverse is false, otherwise the
+// inverse transform is applied
+func (f *FFT3) BatchDepthTransform(data []complex128, b int, inverse bool) []complex128 {
+	if b < 1 {
+		panic("FFT3: The batch size has to be positive")
+	}
+	nc := f.row.Len()
+	nr := f.col.Len()
+	nd := f.depth.Len()
+	if len(f.block) < b*nd {
+		f.block = make([]complex128, b*nd)
+	}
+	batcher, canBatch := f.depth.(BatchTransform)
+	line := make([]complex128, nd)
+	for _, r := range f.rows {
+		for c := 0; c < nc; c += b {
+			size := b
+			if c+size > nc {
+				size = nc - c
+			}
+			block := f.block[:size*nd]
+			for d := 0; d < nd; d++ {
+				start := d*nr*nc + r*nc + c
+				copy(block[d*size:(d+1)*size], data[start:start+size])
+			}
+
+			switch {
+			case canBatch && inverse:
+				batcher.SequenceBatch(block, size)
+			case canBatch:
+				batcher.CoefficientsBatch(block, size)
+			default:
+				op := f.depth.Coefficients
+				if inverse {
+					op = f.depth.Sequence
+				}
+				for j := 0; j < size; j++ {
+					for d := range line {
+						line[d] = block[d*size+j]
+					}
+					op(line, line)
+					for d, v := range line {
+						block[d*size+j] = v
+					}
+				}
+			}
+
+			for d := 0; d < nd; d++ {
+				start := d*nr*nc + r*nc + c
+				copy(data[start:start+size], block[d*size:(d+1)*size])
+			}
+		}
+	}
+	return data
+}
+
+// depthPass performs the transform over the depth, using blocks of lines if batching
+// is enabled
+func (f *FFT3) depthPass(data []complex128, inverse bool) {
+	if f.batch > 1 {
+		f.BatchDepthTransform(data, f.batch, inverse)
+		return
+	}
+	if inverse {
+		f.DepthTransform(data, f.depth.Sequence)
+	} else {
+		f.DepthTransform(data, f.depth.Coefficients)
+	}
+}
+
 // fourierTransform performs forward FFT or backward FFT depending on the functions passed. tRow
 // is the function used to perform FT over rows, tCol is the function used to perform FT over columns
-// and tDepth is the function used to perform FT in the third direction
-func (f *FFT3) fourierTransform(data []complex128, tRow GonumFT, tCol GonumFT, tDepth GonumFT) []complex128 {
+// and the inverse transform is applied in the third direction if inverse is true
+func (f *FFT3) fourierTransform(data []complex128, tRow GonumFT, tCol GonumFT, inverse bool) []complex128 {
 	if len(data) != f.row.Len()*f.col.Len()*f.depth.Len() {
 		panic("FFT3: Inconsistent length of data")
 	}
 	f.RowTransform(data, tRow)
 	f.ColTransform(data, tCol)
-	f.DepthTransform(data, tDepth)
+	f.depthPass(data, inverse)
 	return data
 }
 
 // FFT performs forward fourier transform. The length of the passed array has to be equal to
 // nr*nc*nd, where nr, nc and nd are the values passed to NewFFT3
 func (f *FFT3) FFT(data []complex128) []complex128 {
-	return f.fourierTransform(data, f.row.Coefficients, f.col.Coefficients, f.depth.Coefficients)
+	return f.fourierTransform(data, f.row.Coefficients, f.col.Coefficients, false)
 }
 
 // IFFT performs the inverse fourier transform. The length of the passed array has to match
 // the one returned by FFT (e.g. nr*nc*nd)
 func (f *FFT3) IFFT(data []complex128) []complex128 {
-	return f.fourierTransform(data, f.row.Sequence, f.col.Sequence, f.depth.Sequence)
+	return f.fourierTransform(data, f.row.Sequence, f.col.Sequence, true)
 }
 
 // Freq returns the frequency correpsondex to index i in the array returned
@@ -12,13 +12,18 @@ type FFT2Par struct {
 // the FFTs. Note that both the number of rows and the number of columns has to be
 // divisible by the number of workers
 func NewFFT2Par(nr, nc, nWork int) *FFT2Par {
+	return NewFFT2ParWith(nr, nc, nWork, defaultBackend)
+}
+
+// NewFFT2ParWith returns a new parallel FFT2 that uses the given backend
+func NewFFT2ParWith(nr, nc, nWork int, backend string) *FFT2Par {
 	if nr%nWork != 0 || nc%nWork != 0 {
 		panic("fftpar: The number of rows and columns has to be divisible by the number of workers")
 	}
 	var ftPar FFT2Par
 	ftPar.Transformers = make([]*FFT2, nWork)
 	for i := 0; i < nWork; i++ {
-		ftPar.Transformers[i] = NewFFT2(nr, nc)
+		ftPar.Transformers[i] = NewFFT2With(nr, nc, backend)
 
 		// Split rows and cols among the workers
 		rowsPerWorker := nr / nWork
@@ -90,13 +95,18 @@ type FFT3Par struct {
 // of workers. Note that both the number of rows and the number of columns has to
 // be divisible by the number of workers
 func NewFFT3Par(nr, nc, nd, nWorkers int) *FFT3Par {
+	return NewFFT3ParWith(nr, nc, nd, nWorkers, defaultBackend)
+}
+
+// NewFFT3ParWith returns a new parallel FFT3 that uses the given backend
+func NewFFT3ParWith(nr, nc, nd, nWorkers int, backend string) *FFT3Par {
 	if nr%nWorkers != 0 || nc%nWorkers != 0 {
 		panic("fftpar: The number of rows and the number of columns must be divisible by the number of workers")
 	}
 	var ft FFT3Par
 	ft.Transforms = make([]*FFT3, nWorkers)
 	for i := 0; i < nWorkers; i++ {
-		ft.Transforms[i] = NewFFT3(nr, nc, nd)
+		ft.Transforms[i] = NewFFT3With(nr, nc, nd, backend)
 		rowsPerWorker := nr / nWorkers
 		colsPerWorker := nc / nWorkers
 		ft.Transforms[i].rows = ft.Transforms[i].rows[i*rowsPerWorker : (i+1)*rowsPerWorker]
@@ -105,6 +115,14 @@ func NewFFT3Par(nr, nc, nd, nWorkers int) *FFT3Par {
 	return &ft
 }
 
+// SetBatchSize sets the number of depth lines that are transformed together by each
+// worker (see FFT3.SetBatchSize)
+func (f *FFT3Par) SetBatchSize(b int) {
+	for _, t := range f.Transforms {
+		t.SetBatchSize(b)
+	}
+}
+
 // FFT performs forward fourier transform
 func (f *FFT3Par) FFT(data []complex128) []complex128 {
 	var wg sync.WaitGroup
@@ -130,7 +148,7 @@ func (f *FFT3Par) FFT(data []complex128) []complex128 {
 		wg.Add(1)
 		go func(num int) {
 			defer wg.Done()
-			f.Transforms[num].DepthTransform(data, f.Transforms[num].depth.Coefficients)
+			f.Transforms[num].depthPass(data, false)
 		}(i)
 	}
 	wg.Wait()
@@ -162,7 +180,7 @@ func (f *FFT3Par) IFFT(data []complex128) []complex128 {
 		wg.Add(1)
 		go func(num int) {
 			defer wg.Done()
-			f.Transforms[num].DepthTransform(data, f.Transforms[num].depth.Sequence)
+			f.Transforms[num].depthPass(data, true)
 		}(i)
 	}
 	wg.Wait()
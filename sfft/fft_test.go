@@ -3,83 +3,92 @@ package sfft
 import (
 	"math"
 	"math/cmplx"
+	"math/rand"
 	"testing"
 
 	"gonum.org/v1/gonum/floats"
 )
 
 func TestFFT1(t *testing.T) {
-	ft := NewFFT1(8)
-	data := make([]float64, 8)
-	data[0] = 1.0
-	data[2] = 1.0
-
-	expect := make([]complex128, 5)
-	expect[0] = complex(2.0, 0.0)
-	expect[1] = complex(1.0, -1.0)
-	expect[2] = complex(0.0, 0.0)
-	expect[3] = complex(1.0, 1.0)
-	expect[4] = complex(2.0, 0.0)
-
-	coeff := ft.FFT(data)
-	tol := 1e-10
+	for _, backend := range testBackends {
+		t.Run(backend, func(t *testing.T) {
+			ft := NewFFT1With(8, backend)
+			data := make([]float64, 8)
+			data[0] = 1.0
+			data[2] = 1.0
 
-	for i := range expect {
-		if math.Abs(real(expect[i])-real(coeff[i])) > tol || math.Abs(imag(expect[i])-imag(coeff[i])) > tol {
-			t.Errorf("Expected %v got %v", expect[i], coeff[i])
-		}
-	}
+			expect := make([]complex128, 5)
+			expect[0] = complex(2.0, 0.0)
+			expect[1] = complex(1.0, -1.0)
+			expect[2] = complex(0.0, 0.0)
+			expect[3] = complex(1.0, 1.0)
+			expect[4] = complex(2.0, 0.0)
 
-	inv := ft.IFFT(coeff)
+			coeff := ft.FFT(data)
+			tol := 1e-10
 
-	for i := range inv {
-		if math.Abs(inv[i]-float64(len(data))*data[i]) > tol {
-			t.Errorf("Expected %v got %v\n", inv, data)
-			break
-		}
-	}
+			for i := range expect {
+				if math.Abs(real(expect[i])-real(coeff[i])) > tol || math.Abs(imag(expect[i])-imag(coeff[i])) > tol {
+					t.Errorf("Expected %v got %v", expect[i], coeff[i])
+				}
+			}
 
-	freqs := make([]float64, 5)
-	for i := 0; i < 5; i++ {
-		freqs[i] = ft.Freq(i)
-	}
-	expectFreq := []float64{0.0, 1. / 8., 1. / 4., 3. / 8., 1. / 2.}
-	if !floats.EqualApprox(expectFreq, freqs, 1e-10) {
-		t.Errorf("Unexpected freq. Expected\n%v\ngot\n%v\n", expectFreq, freqs)
+			inv := ft.IFFT(coeff)
+
+			for i := range inv {
+				if math.Abs(inv[i]-float64(len(data))*data[i]) > tol {
+					t.Errorf("Expected %v got %v\n", inv, data)
+					break
+				}
+			}
+
+			freqs := make([]float64, 5)
+			for i := 0; i < 5; i++ {
+				freqs[i] = ft.Freq(i)
+			}
+			expectFreq := []float64{0.0, 1. / 8., 1. / 4., 3. / 8., 1. / 2.}
+			if !floats.EqualApprox(expectFreq, freqs, 1e-10) {
+				t.Errorf("Unexpected freq. Expected\n%v\ngot\n%v\n", expectFreq, freqs)
+			}
+		})
 	}
 }
 
 func TestFFT2(t *testing.T) {
-	data := []float64{0.0, 0.0, 0.0, 0.0, 0.0,
-		0.0, 1.0, 1.0, 0.0, 0.0,
-		0.0, 0.0, 0.0, 0.0, 0.0,
-		0.0, 0.0, 0.0, 0.0, 0.0}
-	expectFFT := make([]complex128, 20)
-	for j := 0; j < 4; j++ {
-		for i := 0; i < 5; i++ {
-			exp1 := complex(0, -0.5*math.Pi*float64(j))
-			exp2 := complex(0, -2.0*math.Pi*float64(i)/5.0)
-			exp3 := complex(0, -4.0*math.Pi*float64(i)/5.0)
-			expectFFT[j*5+i] = cmplx.Exp(exp1) * (cmplx.Exp(exp2) + cmplx.Exp(exp3))
-		}
-	}
-	cmplxData := ToComplex(data)
-	ft := NewFFT2(4, 5)
-	res := ft.FFT(cmplxData)
-	tol := 1e-10
+	for _, backend := range testBackends {
+		t.Run(backend, func(t *testing.T) {
+			data := []float64{0.0, 0.0, 0.0, 0.0, 0.0,
+				0.0, 1.0, 1.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0, 0.0}
+			expectFFT := make([]complex128, 20)
+			for j := 0; j < 4; j++ {
+				for i := 0; i < 5; i++ {
+					exp1 := complex(0, -0.5*math.Pi*float64(j))
+					exp2 := complex(0, -2.0*math.Pi*float64(i)/5.0)
+					exp3 := complex(0, -4.0*math.Pi*float64(i)/5.0)
+					expectFFT[j*5+i] = cmplx.Exp(exp1) * (cmplx.Exp(exp2) + cmplx.Exp(exp3))
+				}
+			}
+			cmplxData := ToComplex(data)
+			ft := NewFFT2With(4, 5, backend)
+			res := ft.FFT(cmplxData)
+			tol := 1e-10
 
-	for i := range res {
-		if !CmplxEqualApprox(res[i], expectFFT[i], tol) {
-			t.Errorf("Expected %v got%v", expectFFT[i], res[i])
-		}
-	}
-	ift := ft.IFFT(res)
+			for i := range res {
+				if !CmplxEqualApprox(res[i], expectFFT[i], tol) {
+					t.Errorf("Expected %v got%v", expectFFT[i], res[i])
+				}
+			}
+			ift := ft.IFFT(res)
 
-	for i := range res {
-		if math.Abs(real(ift[i])-20*data[i]) > tol || math.Abs(imag(ift[i])) > tol {
-			t.Errorf("Expected\n%v\ngot\n%v\n", data, cmplxData)
-			break
-		}
+			for i := range res {
+				if math.Abs(real(ift[i])-20*data[i]) > tol || math.Abs(imag(ift[i])) > tol {
+					t.Errorf("Expected\n%v\ngot\n%v\n", data, cmplxData)
+					break
+				}
+			}
+		})
 	}
 }
 
@@ -119,43 +128,47 @@ func TestFFT2Freq(t *testing.T) {
 }
 
 func TestFFT3(t *testing.T) {
-	data := []float64{0.0, 0.0, 0.0, 0.0,
-		0.0, 0.0, 0.0, 0.0,
-		0.0, 0.0, 0.0, 0.0,
+	for _, backend := range testBackends {
+		t.Run(backend, func(t *testing.T) {
+			data := []float64{0.0, 0.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0,
 
-		0.0, 0.0, 0.0, 0.0,
-		0.0, 1.0, 0.0, 0.0,
-		0.0, 1.0, 0.0, 0.0}
+				0.0, 0.0, 0.0, 0.0,
+				0.0, 1.0, 0.0, 0.0,
+				0.0, 1.0, 0.0, 0.0}
 
-	cmplxData := ToComplex(data)
-	ft := NewFFT3(3, 4, 2)
-	ftData := ft.FFT(cmplxData)
+			cmplxData := ToComplex(data)
+			ft := NewFFT3With(3, 4, 2, backend)
+			ftData := ft.FFT(cmplxData)
 
-	expectFt := make([]complex128, 24)
-	for j := 0; j < 4; j++ {
-		for i := 0; i < 3; i++ {
-			for d := 0; d < 2; d++ {
-				exp1 := cmplx.Exp(complex(0.0, -0.5*math.Pi*float64(j)))
-				exp2 := cmplx.Exp(complex(0.0, -math.Pi*float64(d)))
-				exp3 := cmplx.Exp(complex(0.0, -2.0*math.Pi*float64(i)/3.0))
-				exp4 := cmplx.Exp(complex(0.0, -4.0*math.Pi*float64(i)/3.0))
-				expectFt[d*12+i*4+j] = exp1 * exp2 * (exp3 + exp4)
+			expectFt := make([]complex128, 24)
+			for j := 0; j < 4; j++ {
+				for i := 0; i < 3; i++ {
+					for d := 0; d < 2; d++ {
+						exp1 := cmplx.Exp(complex(0.0, -0.5*math.Pi*float64(j)))
+						exp2 := cmplx.Exp(complex(0.0, -math.Pi*float64(d)))
+						exp3 := cmplx.Exp(complex(0.0, -2.0*math.Pi*float64(i)/3.0))
+						exp4 := cmplx.Exp(complex(0.0, -4.0*math.Pi*float64(i)/3.0))
+						expectFt[d*12+i*4+j] = exp1 * exp2 * (exp3 + exp4)
+					}
+				}
 			}
-		}
-	}
 
-	tol := 1e-10
-	for i := range expectFt {
-		if !CmplxEqualApprox(expectFt[i], ftData[i], tol) {
-			t.Errorf("UnexpectedFT %d: Expected %v got %v", i, expectFt[i], ftData[i])
-		}
-	}
+			tol := 1e-10
+			for i := range expectFt {
+				if !CmplxEqualApprox(expectFt[i], ftData[i], tol) {
+					t.Errorf("UnexpectedFT %d: Expected %v got %v", i, expectFt[i], ftData[i])
+				}
+			}
 
-	ifft := ft.IFFT(ftData)
-	for i := range ifft {
-		if math.Abs(real(ifft[i])-24*data[i]) > tol || math.Abs(imag(ifft[i])) > tol {
-			t.Errorf("Inverse FFT does not match %d: Expected (%f, 0.0) got %v", i, 24*data[i], ifft[i])
-		}
+			ifft := ft.IFFT(ftData)
+			for i := range ifft {
+				if math.Abs(real(ifft[i])-24*data[i]) > tol || math.Abs(imag(ifft[i])) > tol {
+					t.Errorf("Inverse FFT does not match %d: Expected (%f, 0.0) got %v", i, 24*data[i], ifft[i])
+				}
+			}
+		})
 	}
 }
 
@@ -193,3 +206,66 @@ func TestFFT3FreqSymmetry(t *testing.T) {
 		t.Errorf("Expected 8 conjugate pairs. Found %d", num)
 	}
 }
+
+func TestCFFT(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend, func(t *testing.T) {
+			data := []complex128{complex(1.0, 2.0), complex(-1.0, 0.5), complex(3.0, 0.0), complex(0.0, -1.0), complex(2.0, 2.0)}
+			orig := make([]complex128, len(data))
+			copy(orig, data)
+
+			ft := NewCFFTWith(len(data), backend)
+			ft.FFT(data)
+			for k := range data {
+				expect := complex(0.0, 0.0)
+				for n := range orig {
+					expect += orig[n] * cmplx.Exp(complex(0.0, -2.0*math.Pi*float64(k*n)/float64(len(orig))))
+				}
+				if !CmplxEqualApprox(data[k], expect, 1e-10) {
+					t.Errorf("Expected %v got %v", expect, data[k])
+				}
+			}
+
+			ft.IFFT(data)
+			for i := range data {
+				if !CmplxEqualApprox(data[i]/complex(float64(len(data)), 0.0), orig[i], 1e-10) {
+					t.Errorf("Expected %v got %v", orig[i], data[i])
+				}
+			}
+
+			expectFreq := []float64{0.0, 0.2, 0.4, -0.4, -0.2}
+			for i := range expectFreq {
+				if math.Abs(ft.Freq(i)-expectFreq[i]) > 1e-10 {
+					t.Errorf("Expected frequency %f got %f", expectFreq[i], ft.Freq(i))
+				}
+			}
+		})
+	}
+}
+
+func TestFFT3Batch(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(18))
+			nr, nc, nd := 3, 7, 12
+			x := randomCmplx(rng, nr*nc*nd)
+			want := NewFFT3With(nr, nc, nd, backend).FFT(append([]complex128{}, x...))
+			for _, b := range []int{1, 2, 4, 7, 9} {
+				ft := NewFFT3With(nr, nc, nd, backend)
+				ft.SetBatchSize(b)
+				got := ft.FFT(append([]complex128{}, x...))
+				if !cmplxSliceEqualApprox(got, want, 1e-10) {
+					t.Errorf("Batch size %d: Forward transform differs", b)
+				}
+
+				back := ft.IFFT(got)
+				for i := range back {
+					back[i] /= complex(float64(len(x)), 0.0)
+				}
+				if !cmplxSliceEqualApprox(back, x, 1e-10) {
+					t.Errorf("Batch size %d: Inverse does not recover the input", b)
+				}
+			}
+		})
+	}
+}
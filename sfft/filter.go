@@ -0,0 +1,171 @@
+package sfft
+
+import "math"
+
+// Filter is a radial frequency response. Gain returns the factor that the Fourier
+// coefficients at the (non-negative) radial frequency f is multiplied by. Frequencies
+// are measured in the same units as returned by the Freq methods (e.g. the sample
+// spacing is 1.0). Since the gain is real and only depends on the magnitude of the
+// frequency, filtering does not alter the phase of the signal.
+type Filter interface {
+	Gain(f float64) float64
+}
+
+// Ideal is an ideal low-pass filter that removes all frequencies above Cutoff
+type Ideal struct {
+	Cutoff float64
+}
+
+// Gain returns 1 if f is smaller than or equal to the cutoff, and zero otherwise
+func (i Ideal) Gain(f float64) float64 {
+	if f <= i.Cutoff {
+		return 1.0
+	}
+	return 0.0
+}
+
+// Butterworth is a Butterworth low-pass filter. Cutoff is the frequency where the
+// gain is 1/sqrt(2), and Order controls the steepness of the transition
+type Butterworth struct {
+	Cutoff float64
+	Order  int
+}
+
+// Gain returns the gain of the filter at frequency f
+func (b Butterworth) Gain(f float64) float64 {
+	return 1.0 / math.Sqrt(1.0+math.Pow(f/b.Cutoff, 2.0*float64(b.Order)))
+}
+
+// Gaussian is a Gaussian low-pass filter. Cutoff is the standard deviation of the
+// Gaussian
+type Gaussian struct {
+	Cutoff float64
+}
+
+// Gain returns the gain of the filter at frequency f
+func (g Gaussian) Gain(f float64) float64 {
+	return math.Exp(-0.5 * f * f / (g.Cutoff * g.Cutoff))
+}
+
+// HighPass turns a low-pass filter into a high-pass filter
+type HighPass struct {
+	LowPass Filter
+}
+
+// Gain returns the gain of the filter at frequency f
+func (h HighPass) Gain(f float64) float64 {
+	return 1.0 - h.LowPass.Gain(f)
+}
+
+// BandPass passes the frequencies between the cutoff of Lower and the cutoff
+// of Upper. Both are low-pass filters and the cutoff of Lower should be smaller
+// than the one of Upper
+type BandPass struct {
+	Lower Filter
+	Upper Filter
+}
+
+// Gain returns the gain of the filter at frequency f
+func (b BandPass) Gain(f float64) float64 {
+	return b.Upper.Gain(f) * (1.0 - b.Lower.Gain(f))
+}
+
+// Notch removes the frequencies between the cutoff of Lower and the cutoff of
+// Upper. It is the complement of BandPass
+type Notch struct {
+	Lower Filter
+	Upper Filter
+}
+
+// Gain returns the gain of the filter at frequency f
+func (n Notch) Gain(f float64) float64 {
+	return 1.0 - BandPass(n).Gain(f)
+}
+
+// FilterSpectrum1 applies the filter to the coefficients of a 1D transform of a
+// sequence of length n. coeff can either be the half spectrum returned by FFT1
+// (length n/2+1) or the full spectrum returned by CFFT (length n).
+func FilterSpectrum1(coeff []complex128, n int, filt Filter) []complex128 {
+	if len(coeff) != n && len(coeff) != n/2+1 {
+		panic("filter: The number of coefficients has to be n or n/2+1")
+	}
+	for i := range coeff {
+		coeff[i] *= complex(filt.Gain(math.Abs(freqIndex(i, n))), 0.0)
+	}
+	return coeff
+}
+
+// FilterSpectrum2 applies the filter to the coefficients of a 2D transform of an
+// nr x nc array. coeff can either be the full spectrum returned by FFT2 (length nr*nc)
+// or a half spectrum where only the nc/2+1 non-negative column frequencies are
+// stored (length nr*(nc/2+1)).
+func FilterSpectrum2(coeff []complex128, nr, nc int, filt Filter) []complex128 {
+	ncStored := storedColumns(len(coeff), nr*nc, nr*(nc/2+1), nc)
+	forEachFreq2(nr, nc, ncStored, func(idx int, fr, fc float64) {
+		coeff[idx] *= complex(filt.Gain(math.Sqrt(fr*fr+fc*fc)), 0.0)
+	})
+	return coeff
+}
+
+// FilterSpectrum3 applies the filter to the coefficients of a 3D transform of an
+// nr x nc x nd array. coeff can either be the full spectrum returned by FFT3 (length
+// nr*nc*nd) or a half spectrum where only the nc/2+1 non-negative column frequencies
+// are stored (length nr*(nc/2+1)*nd).
+func FilterSpectrum3(coeff []complex128, nr, nc, nd int, filt Filter) []complex128 {
+	ncStored := storedColumns(len(coeff), nr*nc*nd, nr*(nc/2+1)*nd, nc)
+	forEachFreq3(nr, nc, nd, ncStored, func(idx int, fr, fc, fd float64) {
+		coeff[idx] *= complex(filt.Gain(math.Sqrt(fr*fr+fc*fc+fd*fd)), 0.0)
+	})
+	return coeff
+}
+
+// storedColumns returns the number of stored columns given the length of a spectrum,
+// the length of the full and the half spectrum and the number of columns
+func storedColumns(length, full, half, nc int) int {
+	switch length {
+	case full:
+		return nc
+	case half:
+		return nc/2 + 1
+	default:
+		panic("filter: The length of the spectrum does not match a full or half spectrum")
+	}
+}
+
+// Filter1 applies the filter to a real sequence in-place
+func Filter1(data []float64, filt Filter) []float64 {
+	ft := NewFFT1(len(data))
+	coeff := FilterSpectrum1(ft.FFT(data), len(data), filt)
+	ft.ft.Sequence(data, coeff)
+	for i := range data {
+		data[i] /= float64(len(data))
+	}
+	return data
+}
+
+// Filter2 applies the filter in-place to a real 2D array with nr rows and nc columns,
+// stored row-major
+func Filter2(data []float64, nr, nc int, filt Filter) []float64 {
+	if len(data) != nr*nc {
+		panic("filter: Inconsistent size in Filter2")
+	}
+	ft := NewFFT2(nr, nc)
+	coeff := FilterSpectrum2(ft.FFT(ToComplex(data)), nr, nc, filt)
+	ft.IFFT(coeff)
+	for i := range data {
+		data[i] = real(coeff[i]) / float64(len(data))
+	}
+	return data
+}
+
+// Filter3 applies the filter in-place to a real 3D array
+func Filter3(data *Mat3, filt Filter) *Mat3 {
+	nr, nc, nd := data.Dims()
+	ft := NewFFT3(nr, nc, nd)
+	coeff := FilterSpectrum3(ft.FFT(ToComplex(data.Data)), nr, nc, nd, filt)
+	ft.IFFT(coeff)
+	for i := range data.Data {
+		data.Data[i] = real(coeff[i]) / float64(len(data.Data))
+	}
+	return data
+}
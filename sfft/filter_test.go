@@ -0,0 +1,137 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// tones returns a sum of cosines with the given (integer) frequencies sampled at n points
+func tones(n int, freqs ...int) []float64 {
+	data := make([]float64, n)
+	for _, f := range freqs {
+		for i := range data {
+			data[i] += math.Cos(2.0 * math.Pi * float64(f*i) / float64(n))
+		}
+	}
+	return data
+}
+
+func TestFilter1(t *testing.T) {
+	for _, n := range []int{64, 63} {
+		for i, test := range []struct {
+			filt   Filter
+			expect []float64
+		}{
+			{filt: Ideal{Cutoff: 0.1}, expect: tones(n, 3)},
+			{filt: HighPass{LowPass: Ideal{Cutoff: 0.1}}, expect: tones(n, 12, 25)},
+			{filt: BandPass{Lower: Ideal{Cutoff: 0.1}, Upper: Ideal{Cutoff: 0.3}}, expect: tones(n, 12)},
+			{filt: Notch{Lower: Ideal{Cutoff: 0.1}, Upper: Ideal{Cutoff: 0.3}}, expect: tones(n, 3, 25)},
+		} {
+			data := Filter1(tones(n, 3, 12, 25), test.filt)
+			if !floats.EqualApprox(data, test.expect, 1e-10) {
+				t.Errorf("n=%d Test #%d: Unexpected filtered signal", n, i)
+			}
+		}
+	}
+}
+
+func TestFilterProfiles(t *testing.T) {
+	tol := 1e-10
+	bw := Butterworth{Cutoff: 0.2, Order: 4}
+	if math.Abs(bw.Gain(0.2)-1.0/math.Sqrt(2.0)) > tol || math.Abs(bw.Gain(0.0)-1.0) > tol {
+		t.Errorf("Unexpected Butterworth gain")
+	}
+	if bw.Gain(0.4) > 1.0/16.0 {
+		t.Errorf("Butterworth filter does not decay as expected. Gain %f", bw.Gain(0.4))
+	}
+
+	g := Gaussian{Cutoff: 0.1}
+	if math.Abs(g.Gain(0.1)-math.Exp(-0.5)) > tol {
+		t.Errorf("Unexpected Gaussian gain. Expected %f got %f", math.Exp(-0.5), g.Gain(0.1))
+	}
+
+	hp := HighPass{LowPass: g}
+	if math.Abs(hp.Gain(0.1)+g.Gain(0.1)-1.0) > tol {
+		t.Errorf("High-pass is not the complement of the low-pass filter")
+	}
+}
+
+func TestFilterSpectrum1Half(t *testing.T) {
+	n := 16
+	data := tones(n, 1, 6)
+	full := ToComplex(data)
+	NewCFFT(n).FFT(full)
+	half := NewFFT1(n).FFT(data)
+
+	filt := Butterworth{Cutoff: 0.2, Order: 2}
+	FilterSpectrum1(full, n, filt)
+	FilterSpectrum1(half, n, filt)
+	for i := range half {
+		if !CmplxEqualApprox(half[i], full[i], 1e-10) {
+			t.Errorf("Half and full spectra differ at %d: %v != %v", i, half[i], full[i])
+		}
+	}
+}
+
+func TestFilter2(t *testing.T) {
+	nr, nc := 8, 10
+	low := make([]float64, nr*nc)
+	data := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			low[i*nc+j] = math.Cos(2.0 * math.Pi * float64(i) / float64(nr))
+			data[i*nc+j] = low[i*nc+j] + math.Sin(2.0*math.Pi*(3.0*float64(i)/float64(nr)+4.0*float64(j)/float64(nc)))
+		}
+	}
+
+	Filter2(data, nr, nc, Ideal{Cutoff: 0.2})
+	if !floats.EqualApprox(data, low, 1e-10) {
+		t.Errorf("Unexpected result from Filter2")
+	}
+}
+
+func TestFilterSpectrum2Half(t *testing.T) {
+	nr, nc := 4, 6
+	data := make([]complex128, nr*nc)
+	for i := range data {
+		data[i] = complex(float64(i*i%7), 0.0)
+	}
+	NewFFT2(nr, nc).FFT(data)
+	half := make([]complex128, nr*(nc/2+1))
+	for i := 0; i < nr; i++ {
+		copy(half[i*(nc/2+1):(i+1)*(nc/2+1)], data[i*nc:i*nc+nc/2+1])
+	}
+
+	filt := Gaussian{Cutoff: 0.2}
+	FilterSpectrum2(data, nr, nc, filt)
+	FilterSpectrum2(half, nr, nc, filt)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc/2+1; j++ {
+			if !CmplxEqualApprox(half[i*(nc/2+1)+j], data[i*nc+j], 1e-10) {
+				t.Errorf("Half and full spectra differ at (%d, %d)", i, j)
+			}
+		}
+	}
+}
+
+func TestFilter3(t *testing.T) {
+	nr, nc, nd := 4, 6, 8
+	low := NewMat3(nr, nc, nd, nil)
+	data := NewMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				v := 1.0 + math.Cos(2.0*math.Pi*float64(k)/float64(nd))
+				low.Set(i, j, k, v)
+				data.Set(i, j, k, v+math.Cos(2.0*math.Pi*(float64(i)/float64(nr)+2.0*float64(j)/float64(nc))))
+			}
+		}
+	}
+
+	Filter3(data, Ideal{Cutoff: 0.2})
+	if !floats.EqualApprox(data.Data, low.Data, 1e-10) {
+		t.Errorf("Unexpected result from Filter3")
+	}
+}
@@ -0,0 +1,115 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// centredSignal samples fn at t_n = (n - N/2)/sqrt(N)
+func centredSignal(n int, fn func(t float64) complex128) []complex128 {
+	res := make([]complex128, n)
+	for i := range res {
+		res[i] = fn(float64(i-n/2) / math.Sqrt(float64(n)))
+	}
+	return res
+}
+
+func TestFrFTOrderOne(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	for _, n := range []int{8, 9} {
+		x := randomCmplx(rng, n)
+
+		// Centred unitary DFT evaluated from the shifted FFT
+		shifted := make([]complex128, n)
+		for i := range shifted {
+			shifted[i] = x[(i+n/2)%n]
+		}
+		coeff := NewCFFT(n).FFT(shifted)
+		want := make([]complex128, n)
+		for i, v := range coeff {
+			want[(i+n/2)%n] = v / complex(math.Sqrt(float64(n)), 0.0)
+		}
+
+		for _, order := range []float64{1.0, 5.0, -3.0} {
+			got := NewFrFT1(n, order).Transform(x)
+			if !cmplxSliceEqualApprox(got, want, 1e-10) {
+				t.Errorf("n=%d, order %f: Expected\n%v\ngot\n%v", n, order, want, got)
+			}
+		}
+
+		if !cmplxSliceEqualApprox(NewFrFT1(n, 4.0).Transform(x), x, 1e-12) {
+			t.Errorf("n=%d: Order 4 should be the identity", n)
+		}
+		twice := NewFrFT1(n, 1.0).Transform(NewFrFT1(n, 1.0).Transform(x))
+		if !cmplxSliceEqualApprox(NewFrFT1(n, 2.0).Transform(x), twice, 1e-10) {
+			t.Errorf("n=%d: Order 2 should equal two DFTs", n)
+		}
+		back := NewFrFT1(n, -1.0).Transform(NewFrFT1(n, 1.0).Transform(x))
+		if !cmplxSliceEqualApprox(back, x, 1e-10) {
+			t.Errorf("n=%d: Order -1 should invert order 1", n)
+		}
+	}
+}
+
+func TestFrFTGaussian(t *testing.T) {
+	// exp(-pi t^2) is an eigenfunction with unit eigenvalue for all orders
+	n := 64
+	x := centredSignal(n, func(t float64) complex128 {
+		return complex(math.Exp(-math.Pi*t*t), 0.0)
+	})
+	for _, order := range []float64{0.3, 0.7, 1.2, 1.8, 2.5, -0.4} {
+		got := NewFrFT1(n, order).Transform(x)
+		if err := relError(got, x); err > 1e-3 {
+			t.Errorf("Order %f: Relative error %e", order, err)
+		}
+	}
+}
+
+func TestFrFTAdditivity(t *testing.T) {
+	n := 128
+	x := centredSignal(n, func(t float64) complex128 {
+		return cmplx.Exp(complex(-math.Pi*(t-0.8)*(t-0.8), 2.0*t))
+	})
+	for _, test := range []struct {
+		a, b float64
+	}{
+		{0.5, 0.6},
+		{0.3, 0.4},
+		{0.8, 1.1},
+		{0.7, -0.2},
+	} {
+		want := NewFrFT1(n, test.a+test.b).Transform(x)
+		got := NewFrFT1(n, test.b).Transform(NewFrFT1(n, test.a).Transform(x))
+		if err := relError(got, want); err > 1e-3 {
+			t.Errorf("Orders %f + %f: Relative error %e", test.a, test.b, err)
+		}
+	}
+}
+
+func TestFrFT2(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	nr, nc := 6, 5
+	x := randomCmplx(rng, nr*nc)
+	got := NewFrFT2(nr, nc, 0.6, 1.3).Transform(x)
+
+	want := append([]complex128{}, x...)
+	row := NewFrFT1(nr, 0.6)
+	col := NewFrFT1(nc, 1.3)
+	for j := 0; j < nc; j++ {
+		line := make([]complex128, nr)
+		for i := range line {
+			line[i] = want[i*nc+j]
+		}
+		for i, v := range row.Transform(line) {
+			want[i*nc+j] = v
+		}
+	}
+	for i := 0; i < nr; i++ {
+		copy(want[i*nc:], col.Transform(want[i*nc:(i+1)*nc]))
+	}
+	if !cmplxSliceEqualApprox(got, want, 1e-10) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, got)
+	}
+}
@@ -0,0 +1,118 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Goertzel returns the DFT of data at bin k
+//
+//	X_k = sum_n x_n exp(-2 pi i k n/N)
+//
+// where N is the length of data. For integer k in [0, N/2], X_k equals the coefficient at
+// index k returned by FFT1. Non-integer bins evaluate the discrete time Fourier transform
+// at the frequency k/N. The cost is O(N) with a single real multiplication per sample,
+// which is faster than an FFT when only a few bins are needed.
+func Goertzel(data []float64, k float64) complex128 {
+	n := len(data)
+	if n == 0 {
+		return 0.0
+	}
+	omega := 2.0 * math.Pi * k / float64(n)
+	coeff := 2.0 * math.Cos(omega)
+	s1, s2 := 0.0, 0.0
+	for _, v := range data {
+		s1, s2 = v+coeff*s1-s2, s1
+	}
+
+	// y = s_(N-1) - exp(-i omega) s_(N-2) equals exp(i omega (N-1)) X_k
+	y := complex(s1-math.Cos(omega)*s2, math.Sin(omega)*s2)
+	return y * cmplx.Exp(complex(0.0, -omega*float64(n-1)))
+}
+
+// GoertzelBins returns the DFT of data at each of the passed bins (see Goertzel)
+func GoertzelBins(data []float64, bins []float64) []complex128 {
+	res := make([]complex128, len(bins))
+	for i, k := range bins {
+		res[i] = Goertzel(data, k)
+	}
+	return res
+}
+
+// SlidingDFT tracks selected DFT bins of the last N samples of a stream. Each call to
+// Update shifts one sample into the window and updates each bin in O(1) with the
+// recurrence
+//
+//	X_k <- exp(2 pi i k/N) (X_k - x_old + x_new)
+//
+// The window is ordered from the oldest to the newest sample, such that the bins equal
+// the coefficients returned by FFT1 for the last N samples. The window is zero before N
+// samples have been added. Round-off errors accumulate slowly with the number of
+// updates, and Reset can be used to restart the stream.
+type SlidingDFT struct {
+	n       int
+	bins    []int
+	twiddle []complex128
+	coeff   []complex128
+	history []float64
+	pos     int
+}
+
+// NewSlidingDFT returns a sliding DFT over windows of length n that tracks the given
+// bins. The bins have to be in the interval [0, n)
+func NewSlidingDFT(n int, bins ...int) *SlidingDFT {
+	if n < 1 {
+		panic("sdft: The window length has to be positive")
+	}
+	s := &SlidingDFT{
+		n:       n,
+		bins:    append([]int{}, bins...),
+		twiddle: make([]complex128, len(bins)),
+		coeff:   make([]complex128, len(bins)),
+		history: make([]float64, n),
+	}
+	for i, k := range bins {
+		if k < 0 || k >= n {
+			panic("sdft: Bins has to be in the interval [0, n)")
+		}
+		angle := 2.0 * math.Pi * float64(k) / float64(n)
+		s.twiddle[i] = complex(math.Cos(angle), math.Sin(angle))
+	}
+	return s
+}
+
+// Bins returns the tracked bins
+func (s *SlidingDFT) Bins() []int {
+	return s.bins
+}
+
+// Update adds a new sample to the window and returns the current value of the bins. The
+// returned slice is owned by the SlidingDFT and is overwritten by the next update
+func (s *SlidingDFT) Update(x float64) []complex128 {
+	delta := complex(x-s.history[s.pos], 0.0)
+	s.history[s.pos] = x
+	s.pos++
+	if s.pos == s.n {
+		s.pos = 0
+	}
+	for i, w := range s.twiddle {
+		s.coeff[i] = w * (s.coeff[i] + delta)
+	}
+	return s.coeff
+}
+
+// Coefficients returns the current value of the bins
+func (s *SlidingDFT) Coefficients() []complex128 {
+	return s.coeff
+}
+
+// Reset clears the window
+func (s *SlidingDFT) Reset() {
+	for i := range s.history {
+		s.history[i] = 0.0
+	}
+	for i := range s.coeff {
+		s.coeff[i] = 0.0
+	}
+	s.pos = 0
+}
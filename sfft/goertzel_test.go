@@ -0,0 +1,75 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestGoertzel(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	for _, n := range []int{1, 16, 17} {
+		x := randomSeq(rng, n)
+		coeff := NewFFT1(n).FFT(append([]float64{}, x...))
+		bins := make([]float64, len(coeff))
+		for i := range bins {
+			bins[i] = float64(i)
+		}
+		if got := GoertzelBins(x, bins); !cmplxSliceEqualApprox(got, coeff, 1e-10) {
+			t.Errorf("n=%d: Expected\n%v\ngot\n%v", n, coeff, got)
+		}
+	}
+}
+
+func TestGoertzelFractionalBin(t *testing.T) {
+	rng := rand.New(rand.NewSource(13))
+	n := 20
+	x := randomSeq(rng, n)
+	k := 3.4
+	var want complex128
+	for i, v := range x {
+		want += complex(v, 0.0) * cmplx.Exp(complex(0.0, -2.0*math.Pi*k*float64(i)/float64(n)))
+	}
+	if got := Goertzel(x, k); !CmplxEqualApprox(got, want, 1e-10) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+}
+
+func TestSlidingDFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	n := 16
+	bins := []int{0, 3, 8, 13}
+	stream := randomSeq(rng, 5*n+3)
+	sdft := NewSlidingDFT(n, bins...)
+	ft := NewFFT1(n)
+	for i, v := range stream {
+		got := sdft.Update(v)
+
+		window := make([]float64, n)
+		for j := range window {
+			if idx := i - n + 1 + j; idx >= 0 {
+				window[j] = stream[idx]
+			}
+		}
+		coeff := ft.FFT(window)
+		for b, k := range bins {
+			want := coeff[k%(n/2+1)]
+			if k > n/2 {
+				want = cmplx.Conj(coeff[n-k])
+			}
+			if !CmplxEqualApprox(got[b], want, 1e-10) {
+				t.Errorf("Sample %d, bin %d: Expected %v got %v", i, k, want, got[b])
+			}
+		}
+	}
+
+	// After a reset the window only contains the last sample
+	sdft.Reset()
+	for b, v := range sdft.Update(1.0) {
+		want := cmplx.Exp(complex(0.0, 2.0*math.Pi*float64(bins[b])/float64(n)))
+		if !CmplxEqualApprox(v, want, 1e-12) {
+			t.Errorf("Bin %d: Expected %v after reset got %v", bins[b], want, v)
+		}
+	}
+}
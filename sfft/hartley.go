@@ -0,0 +1,195 @@
+package sfft
+
+// hartley holds the common parts of the Hartley transforms
+type hartley struct {
+	ft    complexTransformer
+	shape []int
+	work  []complex128
+}
+
+// newHartley returns a new Hartley transform for an array with the given shape in memory
+// order (see newTransformer)
+func newHartley(shape []int) hartley {
+	return hartley{
+		ft:    newTransformer(shape),
+		shape: shape,
+		work:  make([]complex128, prod(shape)),
+	}
+}
+
+// transform performs the forward transform in-place
+func (h *hartley) transform(data []float64) []float64 {
+	if len(data) != len(h.work) {
+		panic("dht: Inconsistent length of data")
+	}
+	for i, v := range data {
+		h.work[i] = complex(v, 0.0)
+	}
+	h.ft.FFT(h.work)
+	for i, v := range h.work {
+		data[i] = real(v) - imag(v)
+	}
+	return data
+}
+
+// inverse performs the inverse transform in-place
+func (h *hartley) inverse(data []float64) []float64 {
+	h.transform(data)
+	n := float64(len(data))
+	for i := range data {
+		data[i] /= n
+	}
+	return data
+}
+
+// negatedIndex returns the index of the frequency -f for each element of a spectrum with
+// the given shape (in memory order)
+func negatedIndex(shape []int) []int {
+	neg := make([]int, prod(shape))
+	for idx := range neg {
+		rem := idx
+		stride := 1
+		for axis := len(shape) - 1; axis >= 0; axis-- {
+			n := shape[axis]
+			i := rem % n
+			rem /= n
+			neg[idx] += ((n - i) % n) * stride
+			stride *= n
+		}
+	}
+	return neg
+}
+
+// hartleyToFourier converts Hartley coefficients to Fourier coefficients
+func hartleyToFourier(h []float64, shape []int) []complex128 {
+	if len(h) != prod(shape) {
+		panic("dht: Inconsistent length of the coefficients")
+	}
+	neg := negatedIndex(shape)
+	coeff := make([]complex128, len(h))
+	for i, v := range h {
+		vn := h[neg[i]]
+		coeff[i] = complex(0.5*(v+vn), 0.5*(vn-v))
+	}
+	return coeff
+}
+
+// fourierToHartley converts Fourier coefficients to Hartley coefficients
+func fourierToHartley(coeff []complex128) []float64 {
+	h := make([]float64, len(coeff))
+	for i, v := range coeff {
+		h[i] = real(v) - imag(v)
+	}
+	return h
+}
+
+// DHT1 performs the discrete Hartley transform of real sequences
+//
+//	H_k = sum_n x_n cas(2 pi n k/N),  cas(t) = cos(t) + sin(t)
+//
+// The Hartley coefficients are related to the Fourier coefficients F by H = Re F - Im F.
+// The transform is its own inverse up to a factor 1/N. A DHT1 uses internal buffers and
+// can not be used from multiple goroutines at the same time.
+type DHT1 struct {
+	h hartley
+}
+
+// NewDHT1 returns a new Hartley transform for sequences of length n
+func NewDHT1(n int) *DHT1 {
+	return &DHT1{h: newHartley([]int{n})}
+}
+
+// Transform performs the forward transform in-place
+func (d *DHT1) Transform(data []float64) []float64 {
+	return d.h.transform(data)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DHT1) Inverse(data []float64) []float64 {
+	return d.h.inverse(data)
+}
+
+// DHT2 performs the two dimensional discrete Hartley transform of real data stored
+// row-major
+//
+//	H_kl = sum_mn x_mn cas(2 pi (m k/nr + n l/nc))
+//
+// The coefficients are stored in the same layout as the coefficients of FFT2.
+type DHT2 struct {
+	h hartley
+}
+
+// NewDHT2 returns a new Hartley transform for an array with nr rows and nc columns
+func NewDHT2(nr, nc int) *DHT2 {
+	return &DHT2{h: newHartley([]int{nr, nc})}
+}
+
+// Transform performs the forward transform in-place
+func (d *DHT2) Transform(data []float64) []float64 {
+	return d.h.transform(data)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DHT2) Inverse(data []float64) []float64 {
+	return d.h.inverse(data)
+}
+
+// DHT3 performs the three dimensional discrete Hartley transform of real data stored in
+// the same way as Mat3 (see DHT2)
+type DHT3 struct {
+	h hartley
+}
+
+// NewDHT3 returns a new Hartley transform for an array with nr rows, nc columns and
+// depth nd
+func NewDHT3(nr, nc, nd int) *DHT3 {
+	return &DHT3{h: newHartley([]int{nd, nr, nc})}
+}
+
+// Transform performs the forward transform in-place
+func (d *DHT3) Transform(data []float64) []float64 {
+	return d.h.transform(data)
+}
+
+// Inverse performs the inverse transform in-place
+func (d *DHT3) Inverse(data []float64) []float64 {
+	return d.h.inverse(data)
+}
+
+// HartleyToFourier1 returns the Fourier coefficients corresponding to the Hartley
+// coefficients of a real sequence, F_k = (H_k + H_-k)/2 - i(H_k - H_-k)/2
+func HartleyToFourier1(h []float64) []complex128 {
+	return hartleyToFourier(h, []int{len(h)})
+}
+
+// FourierToHartley1 returns the Hartley coefficients corresponding to the Fourier
+// coefficients of a real sequence, H = Re F - Im F
+func FourierToHartley1(coeff []complex128) []float64 {
+	return fourierToHartley(coeff)
+}
+
+// HartleyToFourier2 returns the Fourier coefficients corresponding to the Hartley
+// coefficients of a real 2D array with nr rows and nc columns (see HartleyToFourier1)
+func HartleyToFourier2(h []float64, nr, nc int) []complex128 {
+	return hartleyToFourier(h, []int{nr, nc})
+}
+
+// FourierToHartley2 returns the Hartley coefficients corresponding to the Fourier
+// coefficients of a real 2D array (see FourierToHartley1)
+func FourierToHartley2(coeff []complex128) []float64 {
+	return fourierToHartley(coeff)
+}
+
+// HartleyToFourier3 returns the Fourier coefficients corresponding to the Hartley
+// coefficients of a real 3D array (see HartleyToFourier1)
+func HartleyToFourier3(h *Mat3) *CMat3 {
+	nr, nc, nd := h.Dims()
+	return NewCMat3(nr, nc, nd, hartleyToFourier(h.Data, []int{nd, nr, nc}))
+}
+
+// FourierToHartley3 returns the Hartley coefficients corresponding to the Fourier
+// coefficients of a real 3D array (see FourierToHartley1)
+func FourierToHartley3(coeff *CMat3) *Mat3 {
+	nr, nc, nd := coeff.Dims()
+	return NewMat3(nr, nc, nd, fourierToHartley(coeff.Data))
+}
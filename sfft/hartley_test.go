@@ -0,0 +1,95 @@
+package sfft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func cas(t float64) float64 {
+	return math.Cos(t) + math.Sin(t)
+}
+
+func TestDHT1(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for _, n := range []int{1, 6, 7} {
+		x := randomSeq(rng, n)
+		want := make([]float64, n)
+		for k := range want {
+			for i, v := range x {
+				want[k] += v * cas(2.0*math.Pi*float64(i*k)/float64(n))
+			}
+		}
+		dht := NewDHT1(n)
+		got := dht.Transform(append([]float64{}, x...))
+		if !floats.EqualApprox(got, want, 1e-10) {
+			t.Errorf("n=%d: Expected\n%v\ngot\n%v", n, want, got)
+		}
+
+		coeff := NewCFFT(n).FFT(ToComplex(x))
+		if !cmplxSliceEqualApprox(HartleyToFourier1(got), coeff, 1e-10) {
+			t.Errorf("n=%d: Hartley to Fourier conversion failed", n)
+		}
+		if !floats.EqualApprox(FourierToHartley1(coeff), want, 1e-10) {
+			t.Errorf("n=%d: Fourier to Hartley conversion failed", n)
+		}
+
+		if !floats.EqualApprox(dht.Inverse(got), x, 1e-10) {
+			t.Errorf("n=%d: Inverse does not recover the input", n)
+		}
+	}
+}
+
+func TestDHT2(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	nr, nc := 4, 5
+	x := randomSeq(rng, nr*nc)
+	want := make([]float64, nr*nc)
+	for k := 0; k < nr; k++ {
+		for l := 0; l < nc; l++ {
+			for m := 0; m < nr; m++ {
+				for n := 0; n < nc; n++ {
+					arg := 2.0 * math.Pi * (float64(m*k)/float64(nr) + float64(n*l)/float64(nc))
+					want[k*nc+l] += x[m*nc+n] * cas(arg)
+				}
+			}
+		}
+	}
+	dht := NewDHT2(nr, nc)
+	got := dht.Transform(append([]float64{}, x...))
+	if !floats.EqualApprox(got, want, 1e-10) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, got)
+	}
+
+	coeff := NewFFT2(nr, nc).FFT(ToComplex(x))
+	if !cmplxSliceEqualApprox(HartleyToFourier2(got, nr, nc), coeff, 1e-10) {
+		t.Errorf("Hartley to Fourier conversion failed")
+	}
+	if !floats.EqualApprox(FourierToHartley2(coeff), want, 1e-10) {
+		t.Errorf("Fourier to Hartley conversion failed")
+	}
+	if !floats.EqualApprox(dht.Inverse(got), x, 1e-10) {
+		t.Errorf("Inverse does not recover the input")
+	}
+}
+
+func TestDHT3(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	nr, nc, nd := 3, 4, 5
+	x := NewMat3(nr, nc, nd, randomSeq(rng, nr*nc*nd))
+	dht := NewDHT3(nr, nc, nd)
+	h := NewMat3(nr, nc, nd, dht.Transform(append([]float64{}, x.Data...)))
+
+	coeff := NewCMat3(nr, nc, nd, NewFFT3(nr, nc, nd).FFT(ToComplex(x.Data)))
+	if !cmplxSliceEqualApprox(HartleyToFourier3(h).Data, coeff.Data, 1e-10) {
+		t.Errorf("Hartley to Fourier conversion failed")
+	}
+	if !floats.EqualApprox(FourierToHartley3(coeff).Data, h.Data, 1e-10) {
+		t.Errorf("Fourier to Hartley conversion failed")
+	}
+	if !floats.EqualApprox(dht.Inverse(h.Data), x.Data, 1e-10) {
+		t.Errorf("Inverse does not recover the input")
+	}
+}
@@ -0,0 +1,82 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Hilbert returns the analytic signal of data. The real part of the analytic signal
+// is equal to data, and the imaginary part is the Hilbert transform of data. It is
+// obtained by removing the negative frequencies of the spectrum and doubling the
+// positive ones.
+func Hilbert(data []float64) []complex128 {
+	n := len(data)
+	if n == 0 {
+		return []complex128{}
+	}
+	coeff := NewFFT1(n).FFT(data)
+	analytic := make([]complex128, n)
+	analytic[0] = coeff[0]
+	for i := 1; i < len(coeff); i++ {
+		if n%2 == 0 && i == n/2 {
+			// The Nyquist frequency is shared between the positive and the negative half
+			analytic[i] = coeff[i]
+		} else {
+			analytic[i] = 2.0 * coeff[i]
+		}
+	}
+
+	NewCFFT(n).IFFT(analytic)
+	for i := range analytic {
+		analytic[i] /= complex(float64(n), 0.0)
+	}
+	return analytic
+}
+
+// Envelope returns the amplitude envelope (i.e. the magnitude) of an analytic signal
+func Envelope(analytic []complex128) []float64 {
+	env := make([]float64, len(analytic))
+	for i := range analytic {
+		env[i] = cmplx.Abs(analytic[i])
+	}
+	return env
+}
+
+// InstPhase returns the instantaneous phase of an analytic signal. The phase is
+// unwrapped such that the difference between consecutive values never exceeds pi
+func InstPhase(analytic []complex128) []float64 {
+	phase := make([]float64, len(analytic))
+	for i := range analytic {
+		phase[i] = cmplx.Phase(analytic[i])
+	}
+	Unwrap(phase)
+	return phase
+}
+
+// InstFreq returns the instantaneous frequency of an analytic signal sampled with
+// sample rate fs. The frequency is obtained from the forward difference of the
+// instantaneous phase, hence the returned slice is one element shorter than analytic.
+func InstFreq(analytic []complex128, fs float64) []float64 {
+	if len(analytic) < 2 {
+		return []float64{}
+	}
+	phase := InstPhase(analytic)
+	freq := make([]float64, len(phase)-1)
+	for i := range freq {
+		freq[i] = fs * (phase[i+1] - phase[i]) / (2.0 * math.Pi)
+	}
+	return freq
+}
+
+// Unwrap unwraps a sequence of angles in-place by adding multiples of 2*pi such that
+// the jump between consecutive values is never larger than pi
+func Unwrap(phase []float64) {
+	offset := 0.0
+	for i := 1; i < len(phase); i++ {
+		diff := phase[i] + offset - phase[i-1]
+		if diff > math.Pi || diff < -math.Pi {
+			offset -= 2.0 * math.Pi * math.Round(diff/(2.0*math.Pi))
+		}
+		phase[i] += offset
+	}
+}
@@ -0,0 +1,70 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestHilbertCosine(t *testing.T) {
+	for _, n := range []int{64, 65} {
+		omega := 2.0 * math.Pi * 5.0 / float64(n)
+		data := make([]float64, n)
+		for i := range data {
+			data[i] = math.Cos(omega * float64(i))
+		}
+
+		analytic := Hilbert(data)
+		for i := range analytic {
+			expect := complex(math.Cos(omega*float64(i)), math.Sin(omega*float64(i)))
+			if !CmplxEqualApprox(analytic[i], expect, 1e-10) {
+				t.Errorf("n=%d: Expected %v got %v", n, expect, analytic[i])
+				break
+			}
+		}
+
+		env := Envelope(analytic)
+		if !floats.EqualApprox(env, ones(n), 1e-10) {
+			t.Errorf("n=%d: Expected unit envelope. Got %v", n, env)
+		}
+
+		freq := InstFreq(analytic, 2.0)
+		for i := range freq {
+			if math.Abs(freq[i]-2.0*5.0/float64(n)) > 1e-10 {
+				t.Errorf("n=%d: Expected instantaneous frequency %f got %f", n, 2.0*5.0/float64(n), freq[i])
+				break
+			}
+		}
+
+		phase := InstPhase(analytic)
+		if math.Abs(phase[n-1]-omega*float64(n-1)) > 1e-10 {
+			t.Errorf("n=%d: Phase was not unwrapped. Expected %f got %f", n, omega*float64(n-1), phase[n-1])
+		}
+	}
+}
+
+func TestHilbertAmplitudeModulation(t *testing.T) {
+	n := 1024
+	data := make([]float64, n)
+	expect := make([]float64, n)
+	for i := range data {
+		x := 2.0 * math.Pi * float64(i) / float64(n)
+		expect[i] = 1.0 + 0.5*math.Cos(3.0*x)
+		data[i] = expect[i] * math.Cos(100.0*x)
+	}
+
+	env := Envelope(Hilbert(data))
+	if !floats.EqualApprox(env, expect, 1e-10) {
+		t.Errorf("Envelope does not match the modulation")
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	phase := []float64{3.0, -3.0, -2.5, 2.9, 0.0}
+	Unwrap(phase)
+	expect := []float64{3.0, 2.0*math.Pi - 3.0, 2.0*math.Pi - 2.5, 2.9, 0.0}
+	if !floats.EqualApprox(phase, expect, 1e-10) {
+		t.Errorf("Expected %v got %v", expect, phase)
+	}
+}
@@ -0,0 +1,561 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// maxRadix is the largest prime factor handled by the generic odd radix butterfly.
+// Lengths with larger prime factors are transformed with Bluestein's algorithm
+const maxRadix = 31
+
+// stage holds the information needed for one pass of the Stockham algorithm. After the
+// pass, the data consists of n/(l*radix) interleaved transforms of length l*radix.
+// twiddle holds the factors exp(-2 pi i u s/(l*radix)) for s = 0, ..., l-1 and
+// u = 1, ..., radix-1, stored contiguously for each s
+type stage struct {
+	radix   int
+	l       int
+	twiddle []complex128
+}
+
+// nativeFFT is a complex FFT implemented with mixed radix Stockham passes using
+// butterflies of radix 2, 3, 4, 5, 8 and the other odd primes up to maxRadix. The
+// Stockham formulation is self-sorting, and the innermost loop runs over contiguous
+// elements that share the same twiddle factor. Lengths with larger prime factors are
+// evaluated with Bluestein's algorithm. A nativeFFT uses internal buffers and can not be
+// used from multiple goroutines at the same time.
+type nativeFFT struct {
+	n      int
+	stages []stage
+	work   []complex128
+
+	// batchWork is the work array for batched transforms
+	batchWork []complex128
+
+	// roots holds the powers of exp(-2 pi i/r) for the odd radices without a dedicated
+	// butterfly
+	roots map[int][]complex128
+
+	// Bluestein's algorithm
+	chirp   []complex128
+	chirpFT []complex128
+	inner   *nativeFFT
+	padded  []complex128
+}
+
+// newNativeFFT returns a new native complex FFT of length n
+func newNativeFFT(n int) *nativeFFT {
+	if n < 1 {
+		panic("native: The length of the transform has to be positive")
+	}
+	f := &nativeFFT{n: n, work: make([]complex128, n)}
+	factors, ok := radixFactors(n)
+	if !ok {
+		f.initBluestein()
+		return f
+	}
+
+	f.roots = make(map[int][]complex128)
+	l := 1
+	for _, r := range factors {
+		size := l * r
+		st := stage{radix: r, l: l, twiddle: make([]complex128, l*(r-1))}
+		for s := 0; s < l; s++ {
+			for u := 1; u < r; u++ {
+				st.twiddle[s*(r-1)+u-1] = unitRoot(u*s, size)
+			}
+		}
+		f.stages = append(f.stages, st)
+		if r%2 == 1 && r > 5 {
+			if _, exists := f.roots[r]; !exists {
+				roots := make([]complex128, r)
+				for i := range roots {
+					roots[i] = unitRoot(i, r)
+				}
+				f.roots[r] = roots
+			}
+		}
+		l = size
+	}
+	return f
+}
+
+// unitRoot returns exp(-2 pi i k/n). The exponent is reduced to the first octant such
+// that the result is accurate for large arguments
+func unitRoot(k, n int) complex128 {
+	k %= n
+	if k < 0 {
+		k += n
+	}
+	switch {
+	case k == 0:
+		return 1.0
+	case 2*k == n:
+		return -1.0
+	case 4*k == n:
+		return complex(0.0, -1.0)
+	case 4*k == 3*n:
+		return complex(0.0, 1.0)
+	}
+	angle := -2.0 * math.Pi * float64(k) / float64(n)
+	return complex(math.Cos(angle), math.Sin(angle))
+}
+
+// radixFactors splits n into radices, preferring radix 8 and 4 for the powers of two.
+// The second return value is false if n has a prime factor larger than maxRadix
+func radixFactors(n int) ([]int, bool) {
+	var factors []int
+	for n%8 == 0 {
+		factors = append(factors, 8)
+		n /= 8
+	}
+	for n%4 == 0 {
+		factors = append(factors, 4)
+		n /= 4
+	}
+	for n%2 == 0 {
+		factors = append(factors, 2)
+		n /= 2
+	}
+	for p := 3; n > 1; p += 2 {
+		if p > maxRadix {
+			return nil, false
+		}
+		for n%p == 0 {
+			factors = append(factors, p)
+			n /= p
+		}
+	}
+	return factors, true
+}
+
+// initBluestein prepares the evaluation of the transform as a convolution with a chirp,
+// which is computed with power of two transforms
+func (f *nativeFFT) initBluestein() {
+	n := f.n
+	m := nextPow2(2*n - 1)
+	f.inner = newNativeFFT(m)
+	f.padded = make([]complex128, m)
+	f.chirp = make([]complex128, n)
+	for k := range f.chirp {
+		// exp(-i pi k^2/n) with k^2 reduced modulo 2n
+		f.chirp[k] = unitRoot((k*k)%(2*n), 2*n)
+	}
+	f.chirpFT = make([]complex128, m)
+	f.chirpFT[0] = 1.0
+	for k := 1; k < n; k++ {
+		c := cmplx.Conj(f.chirp[k])
+		f.chirpFT[k] = c
+		f.chirpFT[m-k] = c
+	}
+	f.inner.forward(f.chirpFT)
+}
+
+// Len returns the length of the transform
+func (f *nativeFFT) Len() int {
+	return f.n
+}
+
+// Coefficients computes the Fourier coefficients of src and stores them in dst. dst and
+// src may be the same slice. If dst is nil, a new slice is allocated
+func (f *nativeFFT) Coefficients(dst, src []complex128) []complex128 {
+	dst = f.prepare(dst, src)
+	f.forward(dst)
+	return dst
+}
+
+// Sequence computes the unnormalized inverse transform of src and stores it in dst. dst
+// and src may be the same slice. If dst is nil, a new slice is allocated
+func (f *nativeFFT) Sequence(dst, src []complex128) []complex128 {
+	dst = f.prepare(dst, src)
+	for i, v := range dst {
+		dst[i] = cmplx.Conj(v)
+	}
+	f.forward(dst)
+	for i, v := range dst {
+		dst[i] = cmplx.Conj(v)
+	}
+	return dst
+}
+
+// prepare checks the lengths and copies src into dst
+func (f *nativeFFT) prepare(dst, src []complex128) []complex128 {
+	if len(src) != f.n {
+		panic("native: Length of the sequence does not match the transform")
+	}
+	if dst == nil {
+		dst = make([]complex128, f.n)
+	} else if len(dst) != f.n {
+		panic("native: Length of the destination does not match the transform")
+	}
+	copy(dst, src)
+	return dst
+}
+
+// forward performs the forward transform in-place
+func (f *nativeFFT) forward(data []complex128) {
+	if f.inner != nil {
+		f.bluestein(data)
+		return
+	}
+	f.stockham(data, f.work)
+}
+
+// stockham applies all passes to data using work as the second buffer. The result is
+// stored in data
+func (f *nativeFFT) stockham(data, work []complex128) {
+	src, dst := data, work
+	for _, st := range f.stages {
+		f.pass(st, src, dst)
+		src, dst = dst, src
+	}
+	if len(f.stages)%2 == 1 {
+		copy(data, work)
+	}
+}
+
+// CoefficientsBatch performs the forward transform in-place of b interleaved sequences.
+// Element i of sequence j is stored at data[i*b + j]. The butterflies operate on all
+// sequences at once, since the interleaved sequences are equivalent to a single
+// Stockham pass with b times as many contiguous elements per twiddle factor. Lengths
+// handled by Bluestein's algorithm are transformed one sequence at the time
+func (f *nativeFFT) CoefficientsBatch(data []complex128, b int) {
+	if len(data) != f.n*b {
+		panic("native: Length of the batch does not match the transform")
+	}
+	if f.inner != nil {
+		for j := 0; j < b; j++ {
+			for i := range f.work {
+				f.work[i] = data[i*b+j]
+			}
+			f.bluestein(f.work)
+			for i, v := range f.work {
+				data[i*b+j] = v
+			}
+		}
+		return
+	}
+	if len(f.batchWork) < len(data) {
+		f.batchWork = make([]complex128, len(data))
+	}
+	f.stockham(data, f.batchWork[:len(data)])
+}
+
+// SequenceBatch performs the unnormalized inverse transform in-place of b interleaved
+// sequences (see CoefficientsBatch)
+func (f *nativeFFT) SequenceBatch(data []complex128, b int) {
+	for i, v := range data {
+		data[i] = cmplx.Conj(v)
+	}
+	f.CoefficientsBatch(data, b)
+	for i, v := range data {
+		data[i] = cmplx.Conj(v)
+	}
+}
+
+// bluestein evaluates the transform in-place as a convolution with a chirp
+func (f *nativeFFT) bluestein(data []complex128) {
+	for i := range f.padded {
+		f.padded[i] = 0.0
+	}
+	for k, v := range data {
+		f.padded[k] = v * f.chirp[k]
+	}
+	f.inner.forward(f.padded)
+	for i, v := range f.chirpFT {
+		f.padded[i] = cmplx.Conj(f.padded[i] * v)
+	}
+	f.inner.forward(f.padded)
+	scale := complex(1.0/float64(len(f.padded)), 0.0)
+	for k := range data {
+		data[k] = cmplx.Conj(f.padded[k]) * f.chirp[k] * scale
+	}
+}
+
+// pass performs one Stockham pass. The input consists of interleaved transforms of
+// length l, where element s of transform j is stored at index s*m*r + j. The output
+// holds transforms of length l*r with element s of transform j at index s*m + j, where
+// m = len(src)/(l*r)
+func (f *nativeFFT) pass(st stage, src, dst []complex128) {
+	r := st.radix
+	l := st.l
+	m := len(src) / (l * r)
+	switch r {
+	case 2:
+		for s := 0; s < l; s++ {
+			w1 := st.twiddle[s]
+			in := src[s*m*2:]
+			out0 := dst[s*m:]
+			out1 := dst[(s+l)*m:]
+			for j := 0; j < m; j++ {
+				a0 := in[j]
+				a1 := in[m+j] * w1
+				out0[j] = a0 + a1
+				out1[j] = a0 - a1
+			}
+		}
+	case 4:
+		for s := 0; s < l; s++ {
+			tw := st.twiddle[s*3 : s*3+3]
+			in := src[s*m*4:]
+			for j := 0; j < m; j++ {
+				a0 := in[j]
+				a1 := in[m+j] * tw[0]
+				a2 := in[2*m+j] * tw[1]
+				a3 := in[3*m+j] * tw[2]
+				b0, b1, b2, b3 := butterfly4(a0, a1, a2, a3)
+				dst[s*m+j] = b0
+				dst[(s+l)*m+j] = b1
+				dst[(s+2*l)*m+j] = b2
+				dst[(s+3*l)*m+j] = b3
+			}
+		}
+	case 3:
+		for s := 0; s < l; s++ {
+			tw := st.twiddle[s*2 : s*2+2]
+			in := src[s*m*3:]
+			for j := 0; j < m; j++ {
+				a0 := in[j]
+				a1 := in[m+j] * tw[0]
+				a2 := in[2*m+j] * tw[1]
+				b0, b1, b2 := butterfly3(a0, a1, a2)
+				dst[s*m+j] = b0
+				dst[(s+l)*m+j] = b1
+				dst[(s+2*l)*m+j] = b2
+			}
+		}
+	case 5:
+		for s := 0; s < l; s++ {
+			tw := st.twiddle[s*4 : s*4+4]
+			in := src[s*m*5:]
+			for j := 0; j < m; j++ {
+				a0 := in[j]
+				a1 := in[m+j] * tw[0]
+				a2 := in[2*m+j] * tw[1]
+				a3 := in[3*m+j] * tw[2]
+				a4 := in[4*m+j] * tw[3]
+				b0, b1, b2, b3, b4 := butterfly5(a0, a1, a2, a3, a4)
+				dst[s*m+j] = b0
+				dst[(s+l)*m+j] = b1
+				dst[(s+2*l)*m+j] = b2
+				dst[(s+3*l)*m+j] = b3
+				dst[(s+4*l)*m+j] = b4
+			}
+		}
+	case 8:
+		var a [8]complex128
+		for s := 0; s < l; s++ {
+			tw := st.twiddle[s*7 : s*7+7]
+			in := src[s*m*8:]
+			for j := 0; j < m; j++ {
+				a[0] = in[j]
+				for u := 1; u < 8; u++ {
+					a[u] = in[u*m+j] * tw[u-1]
+				}
+				butterfly8(&a)
+				for v, b := range a {
+					dst[(s+v*l)*m+j] = b
+				}
+			}
+		}
+	default:
+		roots := f.roots[r]
+		a := make([]complex128, r)
+		for s := 0; s < l; s++ {
+			tw := st.twiddle[s*(r-1) : (s+1)*(r-1)]
+			in := src[s*m*r:]
+			for j := 0; j < m; j++ {
+				a[0] = in[j]
+				for u := 1; u < r; u++ {
+					a[u] = in[u*m+j] * tw[u-1]
+				}
+				for v := 0; v < r; v++ {
+					sum := a[0]
+					idx := 0
+					for u := 1; u < r; u++ {
+						idx += v
+						if idx >= r {
+							idx -= r
+						}
+						sum += a[u] * roots[idx]
+					}
+					dst[(s+v*l)*m+j] = sum
+				}
+			}
+		}
+	}
+}
+
+// mulNegI returns -i*z
+func mulNegI(z complex128) complex128 {
+	return complex(imag(z), -real(z))
+}
+
+// mulReal returns c*z for a real c
+func mulReal(c float64, z complex128) complex128 {
+	return complex(c*real(z), c*imag(z))
+}
+
+// butterfly3 returns the length 3 DFT of a0, a1 and a2
+func butterfly3(a0, a1, a2 complex128) (complex128, complex128, complex128) {
+	const s = 0.86602540378443864676 // sin(2 pi/3)
+	t1 := a1 + a2
+	t2 := a0 - mulReal(0.5, t1)
+	t3 := mulNegI(mulReal(s, a1-a2))
+	return a0 + t1, t2 + t3, t2 - t3
+}
+
+// butterfly5 returns the length 5 DFT of a0, ..., a4. The symmetric and antisymmetric
+// combinations of the pairs (a1, a4) and (a2, a3) give the real and imaginary parts of
+// the rotations
+func butterfly5(a0, a1, a2, a3, a4 complex128) (complex128, complex128, complex128, complex128, complex128) {
+	const (
+		c1 = 0.30901699437494742410  // cos(2 pi/5)
+		c2 = -0.80901699437494742410 // cos(4 pi/5)
+		s1 = 0.95105651629515357212  // sin(2 pi/5)
+		s2 = 0.58778525229247312917  // sin(4 pi/5)
+	)
+	t1 := a1 + a4
+	t2 := a2 + a3
+	t3 := a1 - a4
+	t4 := a2 - a3
+	b1 := a0 + mulReal(c1, t1) + mulReal(c2, t2)
+	b2 := a0 + mulReal(c2, t1) + mulReal(c1, t2)
+	d1 := mulNegI(mulReal(s1, t3) + mulReal(s2, t4))
+	d2 := mulNegI(mulReal(s2, t3) - mulReal(s1, t4))
+	return a0 + t1 + t2, b1 + d1, b2 + d2, b2 - d2, b1 - d1
+}
+
+// butterfly4 returns the length 4 DFT of a0, a1, a2 and a3
+func butterfly4(a0, a1, a2, a3 complex128) (complex128, complex128, complex128, complex128) {
+	t0 := a0 + a2
+	t1 := a0 - a2
+	t2 := a1 + a3
+	t3 := mulNegI(a1 - a3)
+	return t0 + t2, t1 + t3, t0 - t2, t1 - t3
+}
+
+// butterfly8 replaces a by its length 8 DFT. The even and odd elements are transformed
+// with radix 4 butterflies and combined with the powers of exp(-2 pi i/8)
+func butterfly8(a *[8]complex128) {
+	e0, e1, e2, e3 := butterfly4(a[0], a[2], a[4], a[6])
+	o0, o1, o2, o3 := butterfly4(a[1], a[3], a[5], a[7])
+	const h = math.Sqrt2 / 2.0
+	o1 = complex(h*(real(o1)+imag(o1)), h*(imag(o1)-real(o1)))
+	o2 = mulNegI(o2)
+	o3 = complex(h*(imag(o3)-real(o3)), -h*(real(o3)+imag(o3)))
+	a[0], a[4] = e0+o0, e0-o0
+	a[1], a[5] = e1+o1, e1-o1
+	a[2], a[6] = e2+o2, e2-o2
+	a[3], a[7] = e3+o3, e3-o3
+}
+
+// nativeRealFFT is a real FFT using the native complex kernels. Sequences of even length
+// are packed into a complex sequence of half the length.
+type nativeRealFFT struct {
+	n     int
+	cft   *nativeFFT
+	work  []complex128
+	twidd []complex128
+}
+
+// newNativeRealFFT returns a new native real FFT of length n
+func newNativeRealFFT(n int) *nativeRealFFT {
+	f := &nativeRealFFT{n: n}
+	if n%2 == 0 {
+		h := n / 2
+		f.cft = newNativeFFT(h)
+		f.work = make([]complex128, h)
+		f.twidd = make([]complex128, h+1)
+		for k := range f.twidd {
+			f.twidd[k] = unitRoot(k, n)
+		}
+	} else {
+		f.cft = newNativeFFT(n)
+		f.work = make([]complex128, n)
+	}
+	return f
+}
+
+// Len returns the length of the real sequences
+func (f *nativeRealFFT) Len() int {
+	return f.n
+}
+
+// Coefficients computes the n/2+1 non-negative frequency coefficients of the real
+// sequence seq and stores them in dst. If dst is nil, a new slice is allocated
+func (f *nativeRealFFT) Coefficients(dst []complex128, seq []float64) []complex128 {
+	if len(seq) != f.n {
+		panic("native: Length of the sequence does not match the transform")
+	}
+	if dst == nil {
+		dst = make([]complex128, f.n/2+1)
+	} else if len(dst) != f.n/2+1 {
+		panic("native: Length of the destination does not match the transform")
+	}
+	if f.n%2 == 1 {
+		for i, v := range seq {
+			f.work[i] = complex(v, 0.0)
+		}
+		f.cft.forward(f.work)
+		copy(dst, f.work)
+		return dst
+	}
+
+	h := f.n / 2
+	for i := range f.work {
+		f.work[i] = complex(seq[2*i], seq[2*i+1])
+	}
+	f.cft.forward(f.work)
+	for k := 0; k <= h; k++ {
+		zk := f.work[k%h]
+		zc := cmplx.Conj(f.work[(h-k)%h])
+		even := 0.5 * (zk + zc)
+		odd := mulNegI(0.5 * (zk - zc))
+		dst[k] = even + f.twidd[k]*odd
+	}
+	return dst
+}
+
+// Sequence computes the unnormalized inverse transform of the n/2+1 coefficients in
+// coeff and stores the real sequence in dst. If dst is nil, a new slice is allocated
+func (f *nativeRealFFT) Sequence(dst []float64, coeff []complex128) []float64 {
+	if len(coeff) != f.n/2+1 {
+		panic("native: Length of the coefficients does not match the transform")
+	}
+	if dst == nil {
+		dst = make([]float64, f.n)
+	} else if len(dst) != f.n {
+		panic("native: Length of the destination does not match the transform")
+	}
+	if f.n%2 == 1 {
+		for k := range f.work {
+			if k < len(coeff) {
+				f.work[k] = cmplx.Conj(coeff[k])
+			} else {
+				f.work[k] = coeff[f.n-k]
+			}
+		}
+		f.cft.forward(f.work)
+		for i, v := range f.work {
+			dst[i] = real(v)
+		}
+		return dst
+	}
+
+	h := f.n / 2
+	for k := range f.work {
+		xk := coeff[k]
+		xc := cmplx.Conj(coeff[h-k])
+		even := xk + xc
+		odd := (xk - xc) * cmplx.Conj(f.twidd[k])
+		f.work[k] = cmplx.Conj(even + complex(-imag(odd), real(odd)))
+	}
+	f.cft.forward(f.work)
+	for i, v := range f.work {
+		dst[2*i] = real(v)
+		dst[2*i+1] = -imag(v)
+	}
+	return dst
+}
@@ -0,0 +1,153 @@
+package sfft
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/floats"
+)
+
+// nativeTestSizes covers pure radix 2, 4 and 8 lengths, mixed radices, odd primes and
+// lengths with prime factors that are handled by Bluestein's algorithm
+var nativeTestSizes = []int{1, 2, 3, 4, 5, 6, 7, 8, 12, 16, 30, 32, 45, 64, 97, 100, 128, 210, 243, 256, 375, 1000, 2 * 37, 1009}
+
+func TestNativeCmplxFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(15))
+	for _, n := range nativeTestSizes {
+		x := randomCmplx(rng, n)
+		ref := fourier.NewCmplxFFT(n)
+		native := newNativeFFT(n)
+
+		want := ref.Coefficients(nil, x)
+		got := native.Coefficients(nil, x)
+		if !cmplxSliceEqualApprox(got, want, 1e-9) {
+			t.Errorf("n=%d: Forward transform differs from gonum", n)
+		}
+
+		want = ref.Sequence(nil, x)
+		got = native.Sequence(nil, x)
+		if !cmplxSliceEqualApprox(got, want, 1e-9) {
+			t.Errorf("n=%d: Inverse transform differs from gonum", n)
+		}
+
+		// In-place transform
+		inplace := append([]complex128{}, x...)
+		native.Coefficients(inplace, inplace)
+		if !cmplxSliceEqualApprox(inplace, ref.Coefficients(nil, x), 1e-9) {
+			t.Errorf("n=%d: In-place transform differs from gonum", n)
+		}
+	}
+}
+
+func TestNativeRealFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	for _, n := range nativeTestSizes {
+		x := randomSeq(rng, n)
+		ref := fourier.NewFFT(n)
+		native := newNativeRealFFT(n)
+
+		want := ref.Coefficients(nil, x)
+		got := native.Coefficients(nil, x)
+		if !cmplxSliceEqualApprox(got, want, 1e-9) {
+			t.Errorf("n=%d: Forward transform differs from gonum", n)
+		}
+		// The round-off errors of the two backends differ for large prime lengths, and
+		// the inverse is therefore compared relative to the norm of the result
+		seq := ref.Sequence(nil, want)
+		if floats.Distance(native.Sequence(nil, want), seq, 2) > 1e-10*floats.Norm(seq, 2) {
+			t.Errorf("n=%d: Inverse transform differs from gonum", n)
+		}
+	}
+}
+
+func TestNativeBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(20))
+	b := 5
+	for _, n := range nativeTestSizes {
+		x := randomCmplx(rng, n*b)
+		native := newNativeFFT(n)
+		ref := fourier.NewCmplxFFT(n)
+
+		got := append([]complex128{}, x...)
+		native.CoefficientsBatch(got, b)
+		inv := append([]complex128{}, x...)
+		native.SequenceBatch(inv, b)
+		for j := 0; j < b; j++ {
+			line := make([]complex128, n)
+			for i := range line {
+				line[i] = x[i*b+j]
+			}
+			want := ref.Coefficients(nil, line)
+			wantInv := ref.Sequence(nil, line)
+			for i := range line {
+				if !CmplxEqualApprox(got[i*b+j], want[i], 1e-9) || !CmplxEqualApprox(inv[i*b+j], wantInv[i], 1e-9) {
+					t.Errorf("n=%d: Batched transform of line %d differs from gonum", n, j)
+					break
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkCFFT(b *testing.B) {
+	for _, backend := range []string{GonumBackend, NativeBackend} {
+		for _, n := range []int{256, 1000, 4096, 1031} {
+			b.Run(fmt.Sprintf("%s/%d", backend, n), func(b *testing.B) {
+				ft := NewCFFTWith(n, backend)
+				data := randomCmplx(rand.New(rand.NewSource(0)), n)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ft.FFT(data)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkFFT1(b *testing.B) {
+	for _, backend := range []string{GonumBackend, NativeBackend} {
+		for _, n := range []int{256, 1000, 4096} {
+			b.Run(fmt.Sprintf("%s/%d", backend, n), func(b *testing.B) {
+				ft := NewFFT1With(n, backend)
+				data := randomSeq(rand.New(rand.NewSource(0)), n)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ft.FFT(data)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkFFT3(b *testing.B) {
+	for _, backend := range []string{GonumBackend, NativeBackend} {
+		b.Run(backend, func(b *testing.B) {
+			n := 64
+			ft := NewFFT3With(n, n, n, backend)
+			data := randomCmplx(rand.New(rand.NewSource(0)), n*n*n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ft.FFT(data)
+			}
+		})
+	}
+}
+
+func BenchmarkFFT3Batch(b *testing.B) {
+	for _, backend := range []string{GonumBackend, NativeBackend} {
+		for _, batch := range []int{1, 8, 32} {
+			b.Run(fmt.Sprintf("%s/%d", backend, batch), func(b *testing.B) {
+				n := 64
+				ft := NewFFT3With(n, n, n, backend)
+				ft.SetBatchSize(batch)
+				data := randomCmplx(rand.New(rand.NewSource(0)), n*n*n)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ft.depthPass(data, false)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// TimeScheme specifies the time integration scheme
+type TimeScheme int
+
+const (
+	// RK4 is the classical fourth order Runge-Kutta scheme
+	RK4 TimeScheme = iota
+
+	// ETDRK2 is the second order exponential time differencing Runge-Kutta scheme of
+	// Cox and Matthews. The viscous term is integrated exactly, which removes the time
+	// step restriction imposed by the viscosity
+	ETDRK2
+)
+
+// NavierStokes2 is a pseudo-spectral solver for the two dimensional incompressible
+// Navier-Stokes equations on a periodic domain in vorticity-streamfunction form
+//
+//	dw/dt + u dw/dx + v dw/dy = Viscosity * nabla^2 w + F
+//	nabla^2 psi = -w,  u = dpsi/dy,  v = -dpsi/dx
+//
+// where x runs along the columns and y along the rows of the grid. The nonlinear term is
+// evaluated in real space and dealiased with the 2/3-rule. The vorticity is stored in
+// Fourier space between the time steps.
+type NavierStokes2 struct {
+	// Viscosity is the kinematic viscosity
+	Viscosity float64
+
+	// Dt is the time step
+	Dt float64
+
+	// Scheme is the time integration scheme
+	Scheme TimeScheme
+
+	// Forcing is the forcing term F of the vorticity equation in real space. If nil,
+	// there is no forcing
+	Forcing []float64
+
+	ft      *FFT2Par
+	kx      []float64
+	ky      []float64
+	k2      []float64
+	mask    []bool
+	omega   []complex128
+	time    float64
+	work    [4][]complex128
+	etdDt   float64
+	etdVisc float64
+	phi     [3][]complex128
+}
+
+// NewNavierStokes2 returns a new solver on a grid with nr rows and nc columns. length is
+// the size of the domain along the rows (y) and the columns (x). nWorkers is the number of
+// workers used in the Fourier transforms (see FFT2Par). The initial vorticity is zero
+func NewNavierStokes2(nr, nc, nWorkers int, length [2]float64) *NavierStokes2 {
+	ns := &NavierStokes2{
+		ft:    NewFFT2Par(nr, nc, nWorkers),
+		kx:    make([]float64, nr*nc),
+		ky:    make([]float64, nr*nc),
+		k2:    make([]float64, nr*nc),
+		mask:  DealiasMask2(nr, nc),
+		omega: make([]complex128, nr*nc),
+	}
+	for i := range ns.work {
+		ns.work[i] = make([]complex128, nr*nc)
+	}
+
+	forEachFreq2(nr, nc, nc, func(idx int, fr, fc float64) {
+		ns.ky[idx] = 2.0 * math.Pi * fr * float64(nr) / length[0]
+		ns.kx[idx] = 2.0 * math.Pi * fc * float64(nc) / length[1]
+		ns.k2[idx] = ns.kx[idx]*ns.kx[idx] + ns.ky[idx]*ns.ky[idx]
+	})
+	return ns
+}
+
+// SetVorticity sets the vorticity field. w is stored row-major
+func (ns *NavierStokes2) SetVorticity(w []float64) {
+	if len(w) != len(ns.omega) {
+		panic("navierstokes: Inconsistent size of the vorticity field")
+	}
+	for i, v := range w {
+		ns.omega[i] = complex(v, 0.0)
+	}
+	ns.ft.FFT(ns.omega)
+}
+
+// toReal transforms a copy of the coefficients to real space
+func (ns *NavierStokes2) toReal(coeff []complex128) []float64 {
+	work := make([]complex128, len(coeff))
+	copy(work, coeff)
+	ns.ft.IFFT(work)
+	return realScaled(work, len(work))
+}
+
+// Vorticity returns the vorticity field in real space
+func (ns *NavierStokes2) Vorticity() []float64 {
+	return ns.toReal(ns.omega)
+}
+
+// velocityCoeff stores the Fourier coefficients of the velocity components
+// derived from the vorticity coefficients omega in u and v
+func (ns *NavierStokes2) velocityCoeff(omega, u, v []complex128) {
+	for i := range omega {
+		if ns.k2[i] == 0.0 {
+			u[i] = 0.0
+			v[i] = 0.0
+			continue
+		}
+		psi := omega[i] / complex(ns.k2[i], 0.0)
+		u[i] = complex(0.0, ns.ky[i]) * psi
+		v[i] = complex(0.0, -ns.kx[i]) * psi
+	}
+}
+
+// Velocity returns the velocity components along the columns (u) and the rows (v)
+func (ns *NavierStokes2) Velocity() ([]float64, []float64) {
+	u := make([]complex128, len(ns.omega))
+	v := make([]complex128, len(ns.omega))
+	ns.velocityCoeff(ns.omega, u, v)
+	return ns.toReal(u), ns.toReal(v)
+}
+
+// Time returns the simulated time
+func (ns *NavierStokes2) Time() float64 {
+	return ns.time
+}
+
+// Energy returns the mean kinetic energy density 0.5*<u^2 + v^2>
+func (ns *NavierStokes2) Energy() float64 {
+	energy := 0.0
+	for i, w := range ns.omega {
+		if ns.k2[i] > 0.0 {
+			a := cmplx.Abs(w)
+			energy += a * a / ns.k2[i]
+		}
+	}
+	n := float64(len(ns.omega))
+	return 0.5 * energy / (n * n)
+}
+
+// Enstrophy returns the mean enstrophy density 0.5*<w^2>
+func (ns *NavierStokes2) Enstrophy() float64 {
+	enstrophy := 0.0
+	for _, w := range ns.omega {
+		a := cmplx.Abs(w)
+		enstrophy += a * a
+	}
+	n := float64(len(ns.omega))
+	return 0.5 * enstrophy / (n * n)
+}
+
+// nonlinear stores the Fourier coefficients of -u.grad(w) + F in dst
+func (ns *NavierStokes2) nonlinear(omega, dst []complex128) {
+	u := ns.work[0]
+	v := ns.work[1]
+	wx := ns.work[2]
+	wy := ns.work[3]
+	ns.velocityCoeff(omega, u, v)
+	for i := range omega {
+		wx[i] = complex(0.0, ns.kx[i]) * omega[i]
+		wy[i] = complex(0.0, ns.ky[i]) * omega[i]
+	}
+	for _, f := range ns.work {
+		ns.ft.IFFT(f)
+	}
+
+	n := float64(len(omega))
+	for i := range dst {
+		adv := -(real(u[i])*real(wx[i]) + real(v[i])*real(wy[i])) / (n * n)
+		if ns.Forcing != nil {
+			adv += ns.Forcing[i]
+		}
+		dst[i] = complex(adv, 0.0)
+	}
+	ns.ft.FFT(dst)
+	ApplyMask(dst, ns.mask)
+}
+
+// rhs stores the time derivative of the vorticity coefficients in dst
+func (ns *NavierStokes2) rhs(omega, dst []complex128) {
+	ns.nonlinear(omega, dst)
+	for i := range dst {
+		dst[i] -= complex(ns.Viscosity*ns.k2[i], 0.0) * omega[i]
+	}
+}
+
+// stepRK4 advances the vorticity one time step with the RK4 scheme
+func (ns *NavierStokes2) stepRK4() {
+	n := len(ns.omega)
+	dt := complex(ns.Dt, 0.0)
+	var k [4][]complex128
+	for i := range k {
+		k[i] = make([]complex128, n)
+	}
+	tmp := make([]complex128, n)
+
+	ns.rhs(ns.omega, k[0])
+	for i := range tmp {
+		tmp[i] = ns.omega[i] + 0.5*dt*k[0][i]
+	}
+	ns.rhs(tmp, k[1])
+	for i := range tmp {
+		tmp[i] = ns.omega[i] + 0.5*dt*k[1][i]
+	}
+	ns.rhs(tmp, k[2])
+	for i := range tmp {
+		tmp[i] = ns.omega[i] + dt*k[2][i]
+	}
+	ns.rhs(tmp, k[3])
+	for i := range ns.omega {
+		ns.omega[i] += dt * (k[0][i] + 2.0*k[1][i] + 2.0*k[2][i] + k[3][i]) / 6.0
+	}
+}
+
+// updateETD computes the coefficients of the ETDRK2 scheme if the time step or the
+// viscosity has changed since the last time they were calculated
+func (ns *NavierStokes2) updateETD() {
+	if ns.phi[0] != nil && ns.etdDt == ns.Dt && ns.etdVisc == ns.Viscosity {
+		return
+	}
+	for i := range ns.phi {
+		ns.phi[i] = make([]complex128, len(ns.omega))
+	}
+	for i, k2 := range ns.k2 {
+		z := -ns.Viscosity * k2 * ns.Dt
+		var phi1, phi2 float64
+		if math.Abs(z) < 1e-4 {
+			// Use the Taylor expansion to avoid cancellation errors
+			phi1 = 1.0 + z/2.0 + z*z/6.0
+			phi2 = 0.5 + z/6.0 + z*z/24.0
+		} else {
+			phi1 = math.Expm1(z) / z
+			phi2 = (math.Expm1(z) - z) / (z * z)
+		}
+		ns.phi[0][i] = complex(math.Exp(z), 0.0)
+		ns.phi[1][i] = complex(ns.Dt*phi1, 0.0)
+		ns.phi[2][i] = complex(ns.Dt*phi2, 0.0)
+	}
+	ns.etdDt = ns.Dt
+	ns.etdVisc = ns.Viscosity
+}
+
+// stepETDRK2 advances the vorticity one time step with the ETDRK2 scheme
+func (ns *NavierStokes2) stepETDRK2() {
+	ns.updateETD()
+	n := len(ns.omega)
+	nl := make([]complex128, n)
+	nla := make([]complex128, n)
+	a := make([]complex128, n)
+
+	ns.nonlinear(ns.omega, nl)
+	for i := range a {
+		a[i] = ns.phi[0][i]*ns.omega[i] + ns.phi[1][i]*nl[i]
+	}
+	ns.nonlinear(a, nla)
+	for i := range ns.omega {
+		ns.omega[i] = a[i] + ns.phi[2][i]*(nla[i]-nl[i])
+	}
+}
+
+// Step advances the solution one time step
+func (ns *NavierStokes2) Step() {
+	if ns.Forcing != nil && len(ns.Forcing) != len(ns.omega) {
+		panic("navierstokes: Inconsistent size of the forcing")
+	}
+	switch ns.Scheme {
+	case RK4:
+		ns.stepRK4()
+	case ETDRK2:
+		ns.stepETDRK2()
+	default:
+		panic("navierstokes: Unknown time scheme")
+	}
+	ns.time += ns.Dt
+}
+
+// Run advances the solution nSteps time steps
+func (ns *NavierStokes2) Run(nSteps int) {
+	for i := 0; i < nSteps; i++ {
+		ns.Step()
+	}
+}
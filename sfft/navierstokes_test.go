@@ -0,0 +1,122 @@
+package sfft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// taylorGreen returns the vorticity of the Taylor-Green vortex psi = sin(x)*sin(y) on
+// a [0, 2pi) x [0, 2pi) grid
+func taylorGreen(nr, nc int) []float64 {
+	w := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := 2.0 * math.Pi * float64(i) / float64(nr)
+			x := 2.0 * math.Pi * float64(j) / float64(nc)
+			w[i*nc+j] = 2.0 * math.Sin(x) * math.Sin(y)
+		}
+	}
+	return w
+}
+
+func TestNavierStokesTaylorGreen(t *testing.T) {
+	nr, nc := 16, 16
+	for _, scheme := range []TimeScheme{RK4, ETDRK2} {
+		ns := NewNavierStokes2(nr, nc, 2, [2]float64{2.0 * math.Pi, 2.0 * math.Pi})
+		ns.Viscosity = 0.1
+		ns.Dt = 0.01
+		ns.Scheme = scheme
+		w0 := taylorGreen(nr, nc)
+		ns.SetVorticity(w0)
+
+		if math.Abs(ns.Energy()-0.25) > 1e-10 || math.Abs(ns.Enstrophy()-0.5) > 1e-10 {
+			t.Errorf("Scheme %d: Unexpected initial energy %f and enstrophy %f", scheme, ns.Energy(), ns.Enstrophy())
+		}
+
+		u, v := ns.Velocity()
+		for i := 0; i < nr; i++ {
+			for j := 0; j < nc; j++ {
+				y := 2.0 * math.Pi * float64(i) / float64(nr)
+				x := 2.0 * math.Pi * float64(j) / float64(nc)
+				if math.Abs(u[i*nc+j]-math.Sin(x)*math.Cos(y)) > 1e-10 || math.Abs(v[i*nc+j]+math.Cos(x)*math.Sin(y)) > 1e-10 {
+					t.Errorf("Scheme %d: Unexpected velocity at (%d, %d)", scheme, i, j)
+				}
+			}
+		}
+
+		ns.Run(100)
+		decay := math.Exp(-2.0 * ns.Viscosity * ns.Time())
+		expect := make([]float64, len(w0))
+		floats.ScaleTo(expect, decay, w0)
+		if !floats.EqualApprox(ns.Vorticity(), expect, 1e-6) {
+			t.Errorf("Scheme %d: Vorticity does not decay as expected", scheme)
+		}
+		if math.Abs(ns.Energy()-0.25*decay*decay) > 1e-6 {
+			t.Errorf("Scheme %d: Expected energy %f got %f", scheme, 0.25*decay*decay, ns.Energy())
+		}
+	}
+}
+
+func TestNavierStokesInviscidConservation(t *testing.T) {
+	nr, nc := 32, 32
+	rng := rand.New(rand.NewSource(5))
+	phases := make([]float64, 6)
+	for i := range phases {
+		phases[i] = 2.0 * math.Pi * rng.Float64()
+	}
+	w := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := 2.0 * math.Pi * float64(i) / float64(nr)
+			x := 2.0 * math.Pi * float64(j) / float64(nc)
+			for m := 1; m <= 3; m++ {
+				w[i*nc+j] += math.Cos(float64(m)*x+phases[2*m-2]) * math.Sin(float64(4-m)*y+phases[2*m-1])
+			}
+		}
+	}
+
+	for _, scheme := range []TimeScheme{RK4, ETDRK2} {
+		ns := NewNavierStokes2(nr, nc, 4, [2]float64{2.0 * math.Pi, 2.0 * math.Pi})
+		ns.Dt = 1e-3
+		ns.Scheme = scheme
+		ns.SetVorticity(w)
+		energy := ns.Energy()
+		enstrophy := ns.Enstrophy()
+		ns.Run(200)
+
+		tol := 1e-8
+		if scheme == ETDRK2 {
+			tol = 1e-5
+		}
+		if math.Abs(ns.Energy()-energy) > tol*energy || math.Abs(ns.Enstrophy()-enstrophy) > tol*enstrophy {
+			t.Errorf("Scheme %d: Energy (%f -> %f) or enstrophy (%f -> %f) not conserved", scheme, energy, ns.Energy(), enstrophy, ns.Enstrophy())
+		}
+	}
+}
+
+func TestNavierStokesKolmogorovForcing(t *testing.T) {
+	// A shear flow w = A*cos(k*y) has no advection. With forcing F the steady state
+	// satisfies Viscosity*k^2*w = F
+	nr, nc := 16, 8
+	ns := NewNavierStokes2(nr, nc, 2, [2]float64{2.0 * math.Pi, 2.0 * math.Pi})
+	ns.Viscosity = 0.5
+	ns.Dt = 0.05
+	ns.Scheme = ETDRK2
+	k := 3.0
+	ns.Forcing = make([]float64, nr*nc)
+	expect := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			y := 2.0 * math.Pi * float64(i) / float64(nr)
+			ns.Forcing[i*nc+j] = math.Cos(k * y)
+			expect[i*nc+j] = math.Cos(k*y) / (ns.Viscosity * k * k)
+		}
+	}
+	ns.Run(400)
+	if !floats.EqualApprox(ns.Vorticity(), expect, 1e-8) {
+		t.Errorf("Steady state not reached")
+	}
+}
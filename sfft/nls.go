@@ -0,0 +1,126 @@
+package sfft
+
+import "math/cmplx"
+
+// SplitStep integrates the (generalized) nonlinear Schrödinger equation
+//
+//	i dpsi/dt = -D nabla^2 psi + V psi + N(|psi|^2) psi
+//
+// on a periodic grid with the second order (Strang) split-step Fourier method. Each
+// step consists of a half step with the potential and the nonlinearity, a full step
+// with the dispersion (carried out in Fourier space) and another half step with the
+// potential and the nonlinearity. Both sub-steps are unitary, hence the norm of psi
+// is conserved up to round-off errors.
+type SplitStep struct {
+	// Potential is the external potential V at each grid point. If nil, V = 0
+	Potential []float64
+
+	// Nonlinearity returns N as a function of the density |psi|^2. If nil, the equation
+	// is linear. For the focusing cubic Schrödinger equation N(rho) = -rho
+	Nonlinearity func(density float64) float64
+
+	dt         float64
+	dispersion float64
+	ft         complexTransformer
+	dims       []int
+	spacing    []float64
+	linear     []complex128
+}
+
+// newSplitStep initializes a new split-step integrator
+func newSplitStep(ft complexTransformer, dims []int, dt, dispersion float64) *SplitStep {
+	s := &SplitStep{
+		dt:         dt,
+		dispersion: dispersion,
+		ft:         ft,
+		dims:       dims,
+	}
+	spacing := make([]float64, len(dims))
+	for i := range spacing {
+		spacing[i] = 1.0
+	}
+	s.SetSpacing(spacing...)
+	return s
+}
+
+// NewSplitStep1 returns a split-step integrator for a 1D grid with n points. dt is the
+// time step and dispersion is the coefficient D in front of the Laplacian (e.g. 0.5 for
+// the dimensionless Schrödinger equation). The grid spacing is 1.0 (see SetSpacing)
+func NewSplitStep1(n int, dt, dispersion float64) *SplitStep {
+	return newSplitStep(NewCFFT(n), []int{n}, dt, dispersion)
+}
+
+// NewSplitStep2 returns a split-step integrator for a 2D grid with nr rows and nc columns
+// stored row-major. See NewSplitStep1 for the remaining arguments
+func NewSplitStep2(nr, nc int, dt, dispersion float64) *SplitStep {
+	return newSplitStep(NewFFT2(nr, nc), []int{nr, nc}, dt, dispersion)
+}
+
+// NewSplitStep3 returns a split-step integrator for a 3D grid with the same layout as
+// CMat3. See NewSplitStep1 for the remaining arguments
+func NewSplitStep3(nr, nc, nd int, dt, dispersion float64) *SplitStep {
+	return newSplitStep(NewFFT3(nr, nc, nd), []int{nr, nc, nd}, dt, dispersion)
+}
+
+// SetSpacing sets the grid spacing along each axis (rows, columns and depth)
+func (s *SplitStep) SetSpacing(spacing ...float64) {
+	k2 := squaredWaveNumbers(s.dims, spacing)
+	s.spacing = spacing
+	s.linear = make([]complex128, len(k2))
+	for i := range k2 {
+		s.linear[i] = cmplx.Exp(complex(0.0, -s.dispersion*k2[i]*s.dt))
+	}
+}
+
+// halfStep applies the potential and the nonlinearity over half a time step
+func (s *SplitStep) halfStep(psi []complex128) {
+	for i := range psi {
+		phase := 0.0
+		if s.Potential != nil {
+			phase += s.Potential[i]
+		}
+		if s.Nonlinearity != nil {
+			re, im := real(psi[i]), imag(psi[i])
+			phase += s.Nonlinearity(re*re + im*im)
+		}
+		psi[i] *= cmplx.Exp(complex(0.0, -0.5*s.dt*phase))
+	}
+}
+
+// Step advances psi one time step in-place
+func (s *SplitStep) Step(psi []complex128) {
+	if len(psi) != len(s.linear) {
+		panic("splitstep: Inconsistent size of the wave function")
+	}
+	if s.Potential != nil && len(s.Potential) != len(psi) {
+		panic("splitstep: Inconsistent size of the potential")
+	}
+	s.halfStep(psi)
+	s.ft.FFT(psi)
+	n := complex(float64(len(psi)), 0.0)
+	for i := range psi {
+		psi[i] *= s.linear[i] / n
+	}
+	s.ft.IFFT(psi)
+	s.halfStep(psi)
+}
+
+// Run advances psi nSteps time steps in-place
+func (s *SplitStep) Run(psi []complex128, nSteps int) {
+	for i := 0; i < nSteps; i++ {
+		s.Step(psi)
+	}
+}
+
+// Norm returns the integral of |psi|^2 over the domain
+func (s *SplitStep) Norm(psi []complex128) float64 {
+	volume := 1.0
+	for _, d := range s.spacing {
+		volume *= d
+	}
+	norm := 0.0
+	for _, v := range psi {
+		norm += real(v)*real(v) + imag(v)*imag(v)
+	}
+	return norm * volume
+}
@@ -0,0 +1,110 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// soliton returns the bright soliton solution psi(x, t) = amp*sech(amp*(x - vt)) *
+// exp(i*(v*x + (amp^2 - v^2)*t/2)) of the focusing cubic Schrödinger equation
+// i dpsi/dt = -0.5 d^2psi/dx^2 - |psi|^2 psi
+func soliton(x []float64, t, amp, v float64) []complex128 {
+	psi := make([]complex128, len(x))
+	for i, xi := range x {
+		envelope := amp / math.Cosh(amp*(xi-v*t))
+		psi[i] = complex(envelope, 0.0) * cmplx.Exp(complex(0.0, v*xi+0.5*(amp*amp-v*v)*t))
+	}
+	return psi
+}
+
+func TestSplitStepSoliton(t *testing.T) {
+	n := 512
+	length := 40.0
+	dx := length / float64(n)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = -length/2.0 + float64(i)*dx
+	}
+
+	amp, v := 1.5, 2.0
+	dt := 1e-3
+	nSteps := 1000
+	psi := soliton(x, 0.0, amp, v)
+
+	solver := NewSplitStep1(n, dt, 0.5)
+	solver.SetSpacing(dx)
+	solver.Nonlinearity = func(rho float64) float64 { return -rho }
+	norm := solver.Norm(psi)
+	solver.Run(psi, nSteps)
+
+	expect := soliton(x, dt*float64(nSteps), amp, v)
+	for i := range psi {
+		if cmplx.Abs(psi[i]-expect[i]) > 1e-4 {
+			t.Errorf("Soliton differs at x=%f: Expected %v got %v", x[i], expect[i], psi[i])
+			break
+		}
+	}
+
+	// Analytical norm of the soliton is 2*amp
+	if math.Abs(solver.Norm(psi)-norm) > 1e-10*norm || math.Abs(norm-2.0*amp) > 1e-6 {
+		t.Errorf("Norm not conserved. Initial %f final %f", norm, solver.Norm(psi))
+	}
+}
+
+func TestSplitStepNormConservation(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for i, solver := range []*SplitStep{
+		NewSplitStep2(16, 12, 0.01, 0.5),
+		NewSplitStep3(8, 6, 4, 0.01, 1.0),
+	} {
+		nPts := len(solver.linear)
+		psi := make([]complex128, nPts)
+		solver.Potential = make([]float64, nPts)
+		for j := range psi {
+			psi[j] = complex(rng.NormFloat64(), rng.NormFloat64())
+			solver.Potential[j] = rng.Float64()
+		}
+		solver.Nonlinearity = func(rho float64) float64 { return 2.0 * rho }
+		norm := solver.Norm(psi)
+		solver.Run(psi, 100)
+		if math.Abs(solver.Norm(psi)-norm) > 1e-10*norm {
+			t.Errorf("Test #%d: Norm not conserved. Initial %f final %f", i, norm, solver.Norm(psi))
+		}
+	}
+}
+
+func TestSplitStepPlaneWave(t *testing.T) {
+	// A plane wave in a constant potential only acquires a phase
+	nr, nc := 8, 8
+	dx := 0.5
+	ky := 2.0 * math.Pi / (float64(nr) * dx)
+	kx := 2.0 * 2.0 * math.Pi / (float64(nc) * dx)
+	psi := make([]complex128, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			psi[i*nc+j] = cmplx.Exp(complex(0.0, ky*float64(i)*dx+kx*float64(j)*dx))
+		}
+	}
+	expect := make([]complex128, len(psi))
+	copy(expect, psi)
+
+	dt := 0.05
+	pot := 0.3
+	solver := NewSplitStep2(nr, nc, dt, 0.5)
+	solver.SetSpacing(dx, dx)
+	solver.Potential = make([]float64, len(psi))
+	for i := range solver.Potential {
+		solver.Potential[i] = pot
+	}
+	solver.Run(psi, 20)
+
+	phase := cmplx.Exp(complex(0.0, -(0.5*(kx*kx+ky*ky)+pot)*dt*20))
+	for i := range psi {
+		if !CmplxEqualApprox(psi[i], expect[i]*phase, 1e-10) {
+			t.Errorf("Expected %v got %v", expect[i]*phase, psi[i])
+			break
+		}
+	}
+}
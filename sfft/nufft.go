@@ -0,0 +1,300 @@
+package sfft
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/integrate/quad"
+)
+
+// esKernel is the "exponential of semicircle" spreading kernel
+//
+//	phi(t) = exp(beta (sqrt(1 - t^2) - 1)),  |t| <= 1
+//
+// which is zero outside [-1, 1]
+type esKernel struct {
+	width int
+	beta  float64
+}
+
+// newESKernel returns a kernel giving a relative accuracy of approximately tol when
+// the fine grid is twice as large as the number of modes
+func newESKernel(tol float64) esKernel {
+	if tol <= 0.0 || tol >= 1.0 {
+		panic("nufft: The tolerance has to be in the interval (0, 1)")
+	}
+	w := int(math.Ceil(math.Log10(1.0/tol))) + 1
+	if w < 2 {
+		w = 2
+	}
+	if w > 16 {
+		w = 16
+	}
+	return esKernel{width: w, beta: 2.3 * float64(w)}
+}
+
+// eval returns the kernel value at t
+func (k esKernel) eval(t float64) float64 {
+	if math.Abs(t) >= 1.0 {
+		return 0.0
+	}
+	return math.Exp(k.beta * (math.Sqrt(1.0-t*t) - 1.0))
+}
+
+// nufftAxis holds the grid information along one axis
+type nufftAxis struct {
+	// n is the number of modes and fine the size of the upsampled grid
+	n    int
+	fine int
+
+	// corr holds the deconvolution factors h/phi_hat(k) for each mode
+	corr []float64
+}
+
+// newNufftAxis returns the grid information for an axis with n modes. The Fourier
+// transform of the kernel is evaluated by Gauss-Legendre quadrature
+func newNufftAxis(n int, kernel esKernel) nufftAxis {
+	if n < 1 {
+		panic("nufft: The number of modes has to be positive")
+	}
+	fine := 2 * n
+	if fine < 2*kernel.width {
+		fine = 2 * kernel.width
+	}
+	h := 2.0 * math.Pi / float64(fine)
+	alpha := 0.5 * float64(kernel.width) * h
+
+	nq := 4*kernel.width + 16
+	t := make([]float64, nq)
+	weight := make([]float64, nq)
+	quad.Legendre{}.FixedLocations(t, weight, -1.0, 1.0)
+	phi := make([]float64, nq)
+	for i, v := range t {
+		phi[i] = kernel.eval(v)
+	}
+
+	ax := nufftAxis{n: n, fine: fine, corr: make([]float64, n)}
+	for m := range ax.corr {
+		k := freqIndex(m, n) * float64(n)
+		ft := 0.0
+		for i, v := range t {
+			ft += weight[i] * phi[i] * math.Cos(k*alpha*v)
+		}
+		ax.corr[m] = h / (alpha * ft)
+	}
+	return ax
+}
+
+// fineIndex returns the index on the fine grid of mode m
+func (ax nufftAxis) fineIndex(m int) int {
+	k := int(math.Round(freqIndex(m, ax.n) * float64(ax.n)))
+	if k < 0 {
+		k += ax.fine
+	}
+	return k
+}
+
+// NUFFT evaluates non-uniform discrete Fourier transforms between a uniform grid of
+// modes and a set of scattered points. The type 1 transform (non-uniform to uniform) is
+//
+//	f_k = sum_j c_j exp(-i k.x_j)
+//
+// and the type 2 transform (uniform to non-uniform) is
+//
+//	c_j = sum_k f_k exp(i k.x_j)
+//
+// The point coordinates are periodic with period 2 pi, and the modes are integer wave
+// vectors stored in the same layout as the coefficients of CFFT, FFT2 and FFT3. Hence,
+// if the points coincide with a uniform grid x = 2 pi n/N, the type 1 transform equals
+// the FFT. Both transforms spread onto (or interpolate from) a grid that is twice as
+// fine with an exponential of semicircle kernel, and the result has a relative
+// accuracy of approximately the tolerance passed on initialization. A NUFFT uses
+// internal buffers and can not be used from multiple goroutines at the same time.
+type NUFFT struct {
+	axes []nufftAxis
+	ft   complexTransformer
+	work []complex128
+
+	// width is the number of fine grid points each point spreads to along each axis.
+	// start holds the first fine grid index and weight the kernel values for each
+	// point and axis
+	width  int
+	npts   int
+	start  [][]int
+	weight [][]float64
+}
+
+// newNUFFT returns a new transform for the modes and point coordinates given in memory
+// order
+func newNUFFT(modes []int, coords [][]float64, tol float64) *NUFFT {
+	kernel := newESKernel(tol)
+	npts := len(coords[0])
+	for _, x := range coords {
+		if len(x) != npts {
+			panic("nufft: Inconsistent number of coordinates")
+		}
+	}
+	p := &NUFFT{
+		axes:   make([]nufftAxis, len(modes)),
+		width:  kernel.width + 1,
+		npts:   npts,
+		start:  make([][]int, len(modes)),
+		weight: make([][]float64, len(modes)),
+	}
+	fine := make([]int, len(modes))
+	for a, n := range modes {
+		p.axes[a] = newNufftAxis(n, kernel)
+		fine[a] = p.axes[a].fine
+	}
+	p.ft = newTransformer(fine)
+	p.work = make([]complex128, prod(fine))
+
+	for a, x := range coords {
+		nf := p.axes[a].fine
+		h := 2.0 * math.Pi / float64(nf)
+		alpha := 0.5 * float64(kernel.width) * h
+		p.start[a] = make([]int, npts)
+		p.weight[a] = make([]float64, npts*p.width)
+		for j, v := range x {
+			l0 := int(math.Ceil((v - alpha) / h))
+			p.start[a][j] = ((l0 % nf) + nf) % nf
+			for s := 0; s < p.width; s++ {
+				p.weight[a][j*p.width+s] = kernel.eval((float64(l0+s)*h - v) / alpha)
+			}
+		}
+	}
+	return p
+}
+
+// NewNUFFT1 returns a transform between n modes and the points x
+func NewNUFFT1(n int, x []float64, tol float64) *NUFFT {
+	return newNUFFT([]int{n}, [][]float64{x}, tol)
+}
+
+// NewNUFFT2 returns a transform between nr x nc modes and the points (xr[j], xc[j]).
+// xr is the coordinate along the rows and xc along the columns
+func NewNUFFT2(nr, nc int, xr, xc []float64, tol float64) *NUFFT {
+	return newNUFFT([]int{nr, nc}, [][]float64{xr, xc}, tol)
+}
+
+// NewNUFFT3 returns a transform between nr x nc x nd modes and the points
+// (xr[j], xc[j], xd[j]). The modes are stored in the same way as CMat3
+func NewNUFFT3(nr, nc, nd int, xr, xc, xd []float64, tol float64) *NUFFT {
+	return newNUFFT([]int{nd, nr, nc}, [][]float64{xd, xr, xc}, tol)
+}
+
+// NumPoints returns the number of non-uniform points
+func (p *NUFFT) NumPoints() int {
+	return p.npts
+}
+
+// forEachNeighbour calls fn with the fine grid index and the kernel weight of each fine
+// grid point within the support of the kernel centered at point j
+func (p *NUFFT) forEachNeighbour(j int, fn func(idx int, w float64)) {
+	dim := len(p.axes)
+	offset := make([]int, dim)
+	total := 1
+	for range p.axes {
+		total *= p.width
+	}
+	for count := 0; count < total; count++ {
+		idx := 0
+		w := 1.0
+		for a, ax := range p.axes {
+			l := p.start[a][j] + offset[a]
+			if l >= ax.fine {
+				l -= ax.fine
+			}
+			idx = idx*ax.fine + l
+			w *= p.weight[a][j*p.width+offset[a]]
+		}
+		if w != 0.0 {
+			fn(idx, w)
+		}
+		for a := dim - 1; a >= 0; a-- {
+			offset[a]++
+			if offset[a] < p.width {
+				break
+			}
+			offset[a] = 0
+		}
+	}
+}
+
+// forEachMode calls fn with the index of each mode, the corresponding index on the fine
+// grid and the deconvolution factor
+func (p *NUFFT) forEachMode(fn func(m, idx int, corr float64)) {
+	dim := len(p.axes)
+	modes := make([]int, dim)
+	for m := 0; m < p.NumModes(); m++ {
+		idx := 0
+		corr := 1.0
+		for a, ax := range p.axes {
+			idx = idx*ax.fine + ax.fineIndex(modes[a])
+			corr *= ax.corr[modes[a]]
+		}
+		fn(m, idx, corr)
+		for a := dim - 1; a >= 0; a-- {
+			modes[a]++
+			if modes[a] < p.axes[a].n {
+				break
+			}
+			modes[a] = 0
+		}
+	}
+}
+
+// NumModes returns the number of uniform modes
+func (p *NUFFT) NumModes() int {
+	total := 1
+	for _, ax := range p.axes {
+		total *= ax.n
+	}
+	return total
+}
+
+// Type1 returns the modes f_k = sum_j c_j exp(-i k.x_j)
+func (p *NUFFT) Type1(c []complex128) []complex128 {
+	if len(c) != p.npts {
+		panic("nufft: Inconsistent number of values")
+	}
+	for i := range p.work {
+		p.work[i] = 0.0
+	}
+	for j, v := range c {
+		p.forEachNeighbour(j, func(idx int, w float64) {
+			p.work[idx] += complex(w, 0.0) * v
+		})
+	}
+	p.ft.FFT(p.work)
+
+	res := make([]complex128, p.NumModes())
+	p.forEachMode(func(m, idx int, corr float64) {
+		res[m] = p.work[idx] * complex(corr, 0.0)
+	})
+	return res
+}
+
+// Type2 returns the values c_j = sum_k f_k exp(i k.x_j) at the non-uniform points
+func (p *NUFFT) Type2(f []complex128) []complex128 {
+	if len(f) != p.NumModes() {
+		panic("nufft: Inconsistent number of modes")
+	}
+	for i := range p.work {
+		p.work[i] = 0.0
+	}
+	p.forEachMode(func(m, idx int, corr float64) {
+		p.work[idx] = f[m] * complex(corr, 0.0)
+	})
+
+	p.ft.IFFT(p.work)
+
+	res := make([]complex128, p.npts)
+	for j := range res {
+		var sum complex128
+		p.forEachNeighbour(j, func(idx int, w float64) {
+			sum += complex(w, 0.0) * p.work[idx]
+		})
+		res[j] = sum
+	}
+	return res
+}
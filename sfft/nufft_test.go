@@ -0,0 +1,132 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// modeVector returns the integer wave vector of mode m for a grid with the given shape
+func modeVector(m int, shape []int) []float64 {
+	k := make([]float64, len(shape))
+	for a := len(shape) - 1; a >= 0; a-- {
+		n := shape[a]
+		k[a] = math.Round(freqIndex(m%n, n) * float64(n))
+		m /= n
+	}
+	return k
+}
+
+// directNUDFT evaluates the type 1 (sign = -1) and type 2 (sign = 1) sums directly.
+// The shape and the coordinates are given in memory order
+func directNUDFT(values []complex128, shape []int, coords [][]float64, sign float64) []complex128 {
+	nModes := prod(shape)
+	nPts := len(coords[0])
+	phase := func(m, j int) complex128 {
+		arg := 0.0
+		for a, k := range modeVector(m, shape) {
+			arg += k * coords[a][j]
+		}
+		return cmplx.Exp(complex(0.0, sign*arg))
+	}
+	if sign < 0.0 {
+		res := make([]complex128, nModes)
+		for m := range res {
+			for j, v := range values {
+				res[m] += v * phase(m, j)
+			}
+		}
+		return res
+	}
+	res := make([]complex128, nPts)
+	for j := range res {
+		for m, v := range values {
+			res[j] += v * phase(m, j)
+		}
+	}
+	return res
+}
+
+func relError(got, want []complex128) float64 {
+	num := 0.0
+	den := 0.0
+	for i := range want {
+		num += math.Pow(cmplx.Abs(got[i]-want[i]), 2)
+		den += math.Pow(cmplx.Abs(want[i]), 2)
+	}
+	return math.Sqrt(num / den)
+}
+
+func randomPoints(rng *rand.Rand, n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 2.0*math.Pi*rng.Float64() - math.Pi
+	}
+	return x
+}
+
+func randomCmplx(rng *rand.Rand, n int) []complex128 {
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(rng.NormFloat64(), rng.NormFloat64())
+	}
+	return x
+}
+
+func TestNUFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	npts := 60
+	for _, test := range []struct {
+		shape []int
+		build func(coords [][]float64, tol float64) *NUFFT
+	}{
+		{
+			shape: []int{33},
+			build: func(c [][]float64, tol float64) *NUFFT { return NewNUFFT1(33, c[0], tol) },
+		},
+		{
+			shape: []int{10, 7},
+			build: func(c [][]float64, tol float64) *NUFFT { return NewNUFFT2(10, 7, c[0], c[1], tol) },
+		},
+		{
+			// Memory order: depth, rows, columns
+			shape: []int{4, 6, 5},
+			build: func(c [][]float64, tol float64) *NUFFT { return NewNUFFT3(6, 5, 4, c[1], c[2], c[0], tol) },
+		},
+	} {
+		coords := make([][]float64, len(test.shape))
+		for a := range coords {
+			coords[a] = randomPoints(rng, npts)
+		}
+		values := randomCmplx(rng, npts)
+		modes := randomCmplx(rng, prod(test.shape))
+		want1 := directNUDFT(values, test.shape, coords, -1.0)
+		want2 := directNUDFT(modes, test.shape, coords, 1.0)
+
+		for _, tol := range []float64{1e-4, 1e-9} {
+			p := test.build(coords, tol)
+			if err := relError(p.Type1(values), want1); err > 10.0*tol {
+				t.Errorf("Shape %v, tol %e: Type 1 error %e", test.shape, tol, err)
+			}
+			if err := relError(p.Type2(modes), want2); err > 10.0*tol {
+				t.Errorf("Shape %v, tol %e: Type 2 error %e", test.shape, tol, err)
+			}
+		}
+	}
+}
+
+func TestNUFFTUniformPoints(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	n := 16
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 2.0 * math.Pi * float64(i) / float64(n)
+	}
+	values := randomCmplx(rng, n)
+	want := NewCFFT(n).FFT(append([]complex128{}, values...))
+	got := NewNUFFT1(n, x, 1e-10).Type1(values)
+	if !cmplxSliceEqualApprox(got, want, 1e-8) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, got)
+	}
+}
@@ -0,0 +1,219 @@
+package sfft
+
+// complexTransformer is implemented by the multidimensional transforms that operate
+// in-place on complex data (e.g. CFFT, FFT2, FFT2Par, FFT3 and FFT3Par)
+type complexTransformer interface {
+	FFT(data []complex128) []complex128
+	IFFT(data []complex128) []complex128
+}
+
+// SpectralModel describes an evolution equation of the form
+//
+//	dc/dt = L c + S N(c)
+//
+// where L and S are linear operators that are diagonal in Fourier space, and N is a
+// pointwise nonlinear function. L is treated implicitly and S N(c) explicitly by
+// SemiImplicit.
+type SpectralModel interface {
+	// Linear returns the Fourier symbol of L at the squared wave number k2
+	Linear(k2 float64) float64
+
+	// NonlinearSymbol returns the Fourier symbol of S at the squared wave number k2
+	NonlinearSymbol(k2 float64) float64
+
+	// Nonlinear returns N(c)
+	Nonlinear(c float64) float64
+}
+
+// DoubleWell returns the derivative of the double well free energy
+// f(c) = height*c^2*(1 - c)^2, which has minima at c = 0 and c = 1
+func DoubleWell(height float64) func(c float64) float64 {
+	return func(c float64) float64 {
+		return 2.0 * height * c * (1.0 - c) * (1.0 - 2.0*c)
+	}
+}
+
+// CahnHilliard is the Cahn-Hilliard equation for a conserved order parameter
+//
+//	dc/dt = Mobility * nabla^2 (df/dc - Kappa * nabla^2 c)
+type CahnHilliard struct {
+	Mobility float64
+	Kappa    float64
+
+	// DfDc is the derivative of the bulk free energy density (see e.g. DoubleWell)
+	DfDc func(c float64) float64
+}
+
+// Linear returns -Mobility*Kappa*k^4
+func (m CahnHilliard) Linear(k2 float64) float64 {
+	return -m.Mobility * m.Kappa * k2 * k2
+}
+
+// NonlinearSymbol returns -Mobility*k^2
+func (m CahnHilliard) NonlinearSymbol(k2 float64) float64 {
+	return -m.Mobility * k2
+}
+
+// Nonlinear returns the derivative of the free energy
+func (m CahnHilliard) Nonlinear(c float64) float64 {
+	return m.DfDc(c)
+}
+
+// AllenCahn is the Allen-Cahn equation for a non-conserved order parameter
+//
+//	dc/dt = -Mobility * (df/dc - Kappa * nabla^2 c)
+type AllenCahn struct {
+	Mobility float64
+	Kappa    float64
+
+	// DfDc is the derivative of the bulk free energy density (see e.g. DoubleWell)
+	DfDc func(c float64) float64
+}
+
+// Linear returns -Mobility*Kappa*k^2
+func (m AllenCahn) Linear(k2 float64) float64 {
+	return -m.Mobility * m.Kappa * k2
+}
+
+// NonlinearSymbol returns -Mobility
+func (m AllenCahn) NonlinearSymbol(k2 float64) float64 {
+	return -m.Mobility
+}
+
+// Nonlinear returns the derivative of the free energy
+func (m AllenCahn) Nonlinear(c float64) float64 {
+	return m.DfDc(c)
+}
+
+// SemiImplicit integrates a SpectralModel in time on a periodic grid with the
+// semi-implicit Fourier spectral method. In Fourier space a step of length dt reads
+//
+//	c' = (c + dt*S*(N - A*c)) / (1 - dt*L - dt*A*S)
+//
+// where A is the stabilization parameter. Stabilization adds and subtracts a linear
+// term A*S*c, which allows for larger time steps when A is comparable to the maximum
+// of dN/dc.
+type SemiImplicit struct {
+	Model SpectralModel
+	Dt    float64
+
+	// Stabilization is the stabilization parameter A. Zero gives the standard
+	// semi-implicit scheme
+	Stabilization float64
+
+	ft    complexTransformer
+	dims  []int
+	k2    []float64
+	work  []complex128
+	nonlc []complex128
+}
+
+// NewSemiImplicit2 returns a new time stepper for a 2D grid with nr rows and nc columns.
+// nWorkers is the number of workers used in the Fourier transforms (see FFT2Par). The
+// grid spacing is 1.0 (see SetSpacing)
+func NewSemiImplicit2(nr, nc, nWorkers int, model SpectralModel, dt float64) *SemiImplicit {
+	s := &SemiImplicit{
+		Model: model,
+		Dt:    dt,
+		ft:    NewFFT2Par(nr, nc, nWorkers),
+		dims:  []int{nr, nc},
+		work:  make([]complex128, nr*nc),
+		nonlc: make([]complex128, nr*nc),
+	}
+	s.SetSpacing(1.0, 1.0)
+	return s
+}
+
+// NewSemiImplicit3 returns a new time stepper for a 3D grid with nr rows, nc columns
+// and depth nd. The data layout is the same as for Mat3. nWorkers is the number of
+// workers used in the Fourier transforms (see FFT3Par). The grid spacing is 1.0 (see
+// SetSpacing)
+func NewSemiImplicit3(nr, nc, nd, nWorkers int, model SpectralModel, dt float64) *SemiImplicit {
+	s := &SemiImplicit{
+		Model: model,
+		Dt:    dt,
+		ft:    NewFFT3Par(nr, nc, nd, nWorkers),
+		dims:  []int{nr, nc, nd},
+		work:  make([]complex128, nr*nc*nd),
+		nonlc: make([]complex128, nr*nc*nd),
+	}
+	s.SetSpacing(1.0, 1.0, 1.0)
+	return s
+}
+
+// SetSpacing sets the grid spacing along the rows, the columns and (in 3D) the depth
+func (s *SemiImplicit) SetSpacing(spacing ...float64) {
+	s.k2 = squaredWaveNumbers(s.dims, spacing)
+}
+
+// squaredWaveNumbers returns the squared wave number of each element in the spectrum
+// of a 1D (dims = {n}), 2D (dims = {nr, nc}) or 3D (dims = {nr, nc, nd}) array
+func squaredWaveNumbers(dims []int, spacing []float64) []float64 {
+	if len(spacing) != len(dims) {
+		panic("sfft: The number of spacings has to match the number of dimensions")
+	}
+	k2 := make([]float64, prod(dims))
+	var lap [][]complex128
+	for i := range dims {
+		lap = append(lap, derivativeFactors(dims[i], spacing[i], 2))
+	}
+	if len(dims) == 1 {
+		for i := range k2 {
+			k2[i] = -real(lap[0][i])
+		}
+		return k2
+	}
+	if len(dims) == 2 {
+		nr, nc := dims[0], dims[1]
+		for i := 0; i < nr; i++ {
+			for j := 0; j < nc; j++ {
+				k2[i*nc+j] = -real(lap[0][i] + lap[1][j])
+			}
+		}
+		return k2
+	}
+	nr, nc, nd := dims[0], dims[1], dims[2]
+	for k := 0; k < nd; k++ {
+		for i := 0; i < nr; i++ {
+			for j := 0; j < nc; j++ {
+				k2[k*nr*nc+i*nc+j] = -real(lap[0][i] + lap[1][j] + lap[2][k])
+			}
+		}
+	}
+	return k2
+}
+
+// Step advances the field c one time step in-place
+func (s *SemiImplicit) Step(c []float64) {
+	if len(c) != len(s.work) {
+		panic("semiimplicit: Inconsistent size of the field")
+	}
+	for i, v := range c {
+		s.work[i] = complex(v, 0.0)
+		s.nonlc[i] = complex(s.Model.Nonlinear(v), 0.0)
+	}
+	s.ft.FFT(s.work)
+	s.ft.FFT(s.nonlc)
+
+	dt := s.Dt
+	a := s.Stabilization
+	for i, k2 := range s.k2 {
+		sym := s.Model.NonlinearSymbol(k2)
+		num := s.work[i] + complex(dt*sym, 0.0)*(s.nonlc[i]-complex(a, 0.0)*s.work[i])
+		denom := 1.0 - dt*s.Model.Linear(k2) - dt*a*sym
+		s.work[i] = num / complex(denom, 0.0)
+	}
+
+	s.ft.IFFT(s.work)
+	n := float64(len(c))
+	for i := range c {
+		c[i] = real(s.work[i]) / n
+	}
+}
+
+// Run advances the field c nSteps time steps in-place
+func (s *SemiImplicit) Run(c []float64, nSteps int) {
+	for i := 0; i < nSteps; i++ {
+		s.Step(c)
+	}
+}
@@ -0,0 +1,88 @@
+package sfft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestCahnHilliardMassConservation(t *testing.T) {
+	nr, nc := 32, 32
+	rng := rand.New(rand.NewSource(3))
+	for i, test := range []struct {
+		dt   float64
+		stab float64
+	}{
+		{dt: 0.05, stab: 0.0},
+		{dt: 1.0, stab: 4.0},
+	} {
+		c := make([]float64, nr*nc)
+		for j := range c {
+			c[j] = 0.4 + 0.05*(rng.Float64()-0.5)
+		}
+		mass := floats.Sum(c)
+
+		model := CahnHilliard{Mobility: 1.0, Kappa: 0.5, DfDc: DoubleWell(1.0)}
+		stepper := NewSemiImplicit2(nr, nc, 2, model, test.dt)
+		stepper.Stabilization = test.stab
+		stepper.Run(c, int(100.0/test.dt))
+
+		if math.Abs(floats.Sum(c)-mass) > 1e-8*mass {
+			t.Errorf("Test #%d: Mass is not conserved. Initial %f final %f", i, mass, floats.Sum(c))
+		}
+
+		// The system should have phase separated into regions close to 0 and 1
+		if floats.Max(c) < 0.9 || floats.Min(c) > 0.1 {
+			t.Errorf("Test #%d: No phase separation. Min %f max %f", i, floats.Min(c), floats.Max(c))
+		}
+	}
+}
+
+func TestAllenCahnDiffusion(t *testing.T) {
+	// Without a bulk free energy the Allen-Cahn equation reduces to the diffusion
+	// equation, which the implicit scheme solves exactly mode by mode
+	nr, nc, nd := 4, 4, 8
+	spacing := 0.5
+	model := AllenCahn{Mobility: 2.0, Kappa: 0.3, DfDc: func(c float64) float64 { return 0.0 }}
+	dt := 0.1
+	stepper := NewSemiImplicit3(nr, nc, nd, 2, model, dt)
+	stepper.SetSpacing(spacing, spacing, spacing)
+
+	k := 2.0 * math.Pi / (float64(nd) * spacing)
+	c := NewMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for l := 0; l < nd; l++ {
+				c.Set(i, j, l, 1.0+math.Cos(k*float64(l)*spacing))
+			}
+		}
+	}
+
+	nSteps := 10
+	stepper.Run(c.Data, nSteps)
+	factor := math.Pow(1.0/(1.0+dt*model.Mobility*model.Kappa*k*k), float64(nSteps))
+	for l := 0; l < nd; l++ {
+		expect := 1.0 + factor*math.Cos(k*float64(l)*spacing)
+		if math.Abs(c.At(2, 1, l)-expect) > 1e-10 {
+			t.Errorf("Expected %f got %f", expect, c.At(2, 1, l))
+		}
+	}
+}
+
+func TestAllenCahnRelaxation(t *testing.T) {
+	nr, nc := 8, 8
+	c := make([]float64, nr*nc)
+	for i := range c {
+		c[i] = 0.7
+	}
+	model := AllenCahn{Mobility: 1.0, Kappa: 1.0, DfDc: DoubleWell(1.0)}
+	stepper := NewSemiImplicit2(nr, nc, 1, model, 0.5)
+	stepper.Stabilization = 2.0
+	stepper.Run(c, 200)
+
+	if !floats.EqualApprox(c, ones(len(c)), 1e-6) {
+		t.Errorf("Expected the order parameter to relax to 1. Got %f", c[0])
+	}
+}
@@ -0,0 +1,83 @@
+package sfft
+
+import "sync"
+
+// plans holds a pool of 1D transforms for each backend, size and kind (real or
+// complex). The transforms use internal work arrays and can not be shared between
+// goroutines, but they are expensive to create. The pools allow transforms of the same
+// size to reuse plans. The FFT types take their plans from the cache on construction and
+// own them for their lifetime, while CZT and FrFT check out a plan for the duration of a
+// transform and put it back afterwards. A plan must only be put back when no transform
+// is running on it.
+var plans sync.Map
+
+// planKey identifies the pool of plans for a given backend and size
+type planKey struct {
+	backend string
+	n       int
+	real    bool
+}
+
+// plan is a complex FFT from the plan cache. It keeps the name of the backend that
+// created it, such that it is returned to the pool it was taken from
+type plan struct {
+	CmplxTransform
+	backend string
+}
+
+// realPlan is a real FFT from the plan cache
+type realPlan struct {
+	RealTransform
+	backend string
+}
+
+// planPool returns the pool of plans with the given key. The backend is looked up before
+// the pool is created, such that unknown backends are reported immediately
+func planPool(key planKey) *sync.Pool {
+	if pool, ok := plans.Load(key); ok {
+		return pool.(*sync.Pool)
+	}
+	backend := mustBackend(key.backend)
+	pool, _ := plans.LoadOrStore(key, &sync.Pool{
+		New: func() interface{} {
+			if key.real {
+				return &realPlan{RealTransform: backend.NewReal(key.n), backend: key.backend}
+			}
+			return &plan{CmplxTransform: backend.NewCmplx(key.n), backend: key.backend}
+		},
+	})
+	return pool.(*sync.Pool)
+}
+
+// getPlan returns a complex FFT of the given size and backend from the plan cache
+func getPlan(n int, backend string) *plan {
+	return planPool(planKey{backend: backend, n: n}).Get().(*plan)
+}
+
+// putPlan returns a plan obtained from getPlan to the plan cache
+func putPlan(p *plan) {
+	planPool(planKey{backend: p.backend, n: p.Len()}).Put(p)
+}
+
+// getRealPlan returns a real FFT of the given size and backend from the plan cache
+func getRealPlan(n int, backend string) *realPlan {
+	return planPool(planKey{backend: backend, n: n, real: true}).Get().(*realPlan)
+}
+
+// sharedPlans returns one plan for each of the passed sizes. Equal sizes share the same
+// plan, which is safe as long as the transforms are applied one at the time
+func sharedPlans(backend string, sizes ...int) []*plan {
+	res := make([]*plan, len(sizes))
+	for i, n := range sizes {
+		for j := 0; j < i; j++ {
+			if sizes[j] == n {
+				res[i] = res[j]
+				break
+			}
+		}
+		if res[i] == nil {
+			res[i] = getPlan(n, backend)
+		}
+	}
+	return res
+}
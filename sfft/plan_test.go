@@ -0,0 +1,83 @@
+package sfft
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestPlanKeepsBackend(t *testing.T) {
+	for _, backend := range []string{GonumBackend, NativeBackend} {
+		p := getPlan(12, backend)
+		if p.backend != backend || p.Len() != 12 {
+			t.Errorf("Expected a plan of length 12 from %s got %d from %s", backend, p.Len(), p.backend)
+		}
+		putPlan(p)
+
+		r := getRealPlan(12, backend)
+		if r.backend != backend || r.Len() != 12 {
+			t.Errorf("Expected a real plan of length 12 from %s got %d from %s", backend, r.Len(), r.backend)
+		}
+	}
+
+	// A plan returned to the cache must only be handed out for its own backend
+	for i := 0; i < 8; i++ {
+		putPlan(getPlan(20, NativeBackend))
+		if p := getPlan(20, GonumBackend); p.backend != GonumBackend {
+			t.Errorf("Got a plan from %s when asking for %s", p.backend, GonumBackend)
+		}
+	}
+}
+
+func TestSharedPlans(t *testing.T) {
+	ps := sharedPlans(GonumBackend, 8, 6, 8)
+	if ps[0] != ps[2] {
+		t.Errorf("Expected plans of equal size to be shared")
+	}
+	if ps[0] == ps[1] || ps[1].Len() != 6 {
+		t.Errorf("Expected a separate plan of length 6")
+	}
+}
+
+func TestPlansConcurrentWithGC(t *testing.T) {
+	// Hilbert constructs temporary FFT types that become unreachable while their
+	// transforms are running. Their plans must not be handed to other goroutines
+	rng := rand.New(rand.NewSource(21))
+	data := randomSeq(rng, 4096)
+	want := Hilbert(data)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				runtime.GC()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	failures := make([]int, 8)
+	for w := range failures {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 300; i++ {
+				if !cmplxSliceEqualApprox(Hilbert(data), want, 1e-10) {
+					failures[w]++
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(done)
+
+	for w, num := range failures {
+		if num > 0 {
+			t.Errorf("Worker %d: %d corrupted transforms", w, num)
+		}
+	}
+}
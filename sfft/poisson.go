@@ -0,0 +1,448 @@
+package sfft
+
+import "math"
+
+// Boundary specifies the boundary condition along an axis
+type Boundary int
+
+const (
+	// Periodic boundary conditions, where grid point N coincides with grid point 0
+	Periodic Boundary = iota
+
+	// Dirichlet boundary conditions, where the potential vanishes at the (ghost) grid
+	// points -1 and N. The domain length along the axis is (N+1)*spacing
+	Dirichlet
+
+	// Neumann boundary conditions, where the normal derivative of the potential vanishes
+	// at the first and the last grid point. The domain length along the axis is
+	// (N-1)*spacing
+	Neumann
+)
+
+// PoissonOptions holds the parameters used when solving the Poisson equation
+// (nabla^2 phi = f) and the Helmholtz equation (nabla^2 phi - kappa^2 phi = f)
+type PoissonOptions struct {
+	// Spacing is the grid spacing along each axis (rows, columns and depth). If nil,
+	// a spacing of 1.0 is used along all axes
+	Spacing []float64
+
+	// Kappa is the screening parameter of the Helmholtz equation. If zero, the Poisson
+	// equation is solved
+	Kappa float64
+
+	// Gradient specifies whether the gradient of the potential should be calculated
+	Gradient bool
+
+	// Tol is the tolerance used when checking that the source is compatible with the
+	// boundary conditions. For the Poisson equation on a periodic domain, the mean of the
+	// source has to vanish. The check passes if the magnitude of the mean is less than
+	// Tol times the maximum magnitude of the source. If zero, a tolerance of 1e-8 is used
+	Tol float64
+
+	// Boundary is the boundary condition along each axis (rows, columns and depth). If
+	// nil, all axes are periodic. If it has a single item, the boundary condition is used
+	// along all axes
+	Boundary []Boundary
+}
+
+// boundaries returns the boundary condition along each of the dim axes
+func (p PoissonOptions) boundaries(dim int) []Boundary {
+	bcs := make([]Boundary, dim)
+	switch len(p.Boundary) {
+	case 0:
+	case 1:
+		for i := range bcs {
+			bcs[i] = p.Boundary[0]
+		}
+	case dim:
+		copy(bcs, p.Boundary)
+	default:
+		panic("poisson: The length of Boundary has to be one or match the number of dimensions")
+	}
+	return bcs
+}
+
+// spacing returns the grid spacing along each of the dim axes
+func (p PoissonOptions) spacing(dim int) []float64 {
+	if p.Spacing == nil {
+		spacing := make([]float64, dim)
+		for i := range spacing {
+			spacing[i] = 1.0
+		}
+		return spacing
+	}
+	if len(p.Spacing) != dim {
+		panic("poisson: The length of Spacing has to match the number of dimensions")
+	}
+	return p.Spacing
+}
+
+// checkCompatible panics if the mean of the source is not zero within the tolerance.
+// The check is only relevant for the Poisson equation. If weights is not nil, the
+// weighted mean is used
+func (p PoissonOptions) checkCompatible(src, weights []float64) {
+	if p.Kappa != 0.0 {
+		return
+	}
+	tol := p.Tol
+	if tol == 0.0 {
+		tol = 1e-8
+	}
+	mean := 0.0
+	total := 0.0
+	maxVal := 0.0
+	for i, v := range src {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		mean += w * v
+		total += w
+		maxVal = math.Max(maxVal, math.Abs(v))
+	}
+	mean /= total
+	if math.Abs(mean) > tol*maxVal {
+		panic("poisson: The source has to have zero mean to be compatible with the boundary conditions")
+	}
+}
+
+// inverseHelmholtz returns the factor a coefficient with the given value of the Laplacian
+// operator (e.g. -k^2) is multiplied by when solving the Helmholtz equation. The zero mode
+// of the Poisson equation is set to zero, which fixes the mean of the potential to zero
+func inverseHelmholtz(lap, kappa float64) complex128 {
+	denom := lap - kappa*kappa
+	if denom == 0.0 {
+		return 0.0
+	}
+	return complex(1.0/denom, 0.0)
+}
+
+// Poisson2 solves the Poisson equation (or the Helmholtz equation if Kappa is non-zero)
+// on a 2D domain with nr rows and nc columns. src is the source term f stored row-major.
+// The first return value is the potential phi. If opts.Gradient is true, the second
+// return value holds the derivatives of phi along the rows and the columns, otherwise the
+// items are nil. The boundary conditions are periodic unless opts.Boundary is given. When
+// no axis has Dirichlet boundary conditions, the potential of the Poisson equation is
+// only defined up to a constant which is chosen such that its mean is zero, and the
+// function panics if the source does not have zero mean. Along axes with Neumann boundary
+// conditions, the mean is the trapezoidal mean, where the first and the last grid point
+// have half weight.
+func Poisson2(src []float64, nr, nc int, opts PoissonOptions) ([]float64, [2][]float64) {
+	spacing := opts.spacing(2)
+	if bcs := opts.boundaries(2); !allPeriodic(bcs) {
+		phi, grad := poissonBounded(src, []int{nr, nc}, bcs, spacing, opts)
+		return phi, [2][]float64{grad[0], grad[1]}
+	}
+	opts.checkCompatible(src, nil)
+	coeff := forward2(src, nr, nc)
+
+	lr := derivativeFactors(nr, spacing[0], 2)
+	lc := derivativeFactors(nc, spacing[1], 2)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			coeff[i*nc+j] *= inverseHelmholtz(real(lr[i]+lc[j]), opts.Kappa)
+		}
+	}
+
+	phi := backTransform2(coeff, nr, nc, func(i, j int) complex128 { return 1.0 })
+	var grad [2][]float64
+	if opts.Gradient {
+		fr := derivativeFactors(nr, spacing[0], 1)
+		fc := derivativeFactors(nc, spacing[1], 1)
+		grad[0] = backTransform2(coeff, nr, nc, func(i, j int) complex128 { return fr[i] })
+		grad[1] = backTransform2(coeff, nr, nc, func(i, j int) complex128 { return fc[j] })
+	}
+	return phi, grad
+}
+
+// Poisson3 solves the Poisson equation (or the Helmholtz equation if Kappa is non-zero)
+// on a 3D domain. The first return value is the potential phi. If opts.Gradient is true,
+// the second return value holds the derivatives of phi along the rows, the columns and
+// the depth, otherwise the items are nil. The boundary conditions, the gauge and the
+// compatibility check is the same as for Poisson2.
+func Poisson3(src *Mat3, opts PoissonOptions) (*Mat3, [3]*Mat3) {
+	spacing := opts.spacing(3)
+	nr, nc, nd := src.Dims()
+	if bcs := opts.boundaries(3); !allPeriodic(bcs) {
+		// Axes in memory order are depth, rows and columns
+		phi, grad := poissonBounded(src.Data, []int{nd, nr, nc}, []Boundary{bcs[2], bcs[0], bcs[1]},
+			[]float64{spacing[2], spacing[0], spacing[1]}, opts)
+		var grad3 [3]*Mat3
+		if opts.Gradient {
+			for i, g := range [3][]float64{grad[1], grad[2], grad[0]} {
+				grad3[i] = NewMat3(nr, nc, nd, g)
+			}
+		}
+		return NewMat3(nr, nc, nd, phi), grad3
+	}
+	opts.checkCompatible(src.Data, nil)
+	coeff := forward3(src)
+
+	lr := derivativeFactors(nr, spacing[0], 2)
+	lc := derivativeFactors(nc, spacing[1], 2)
+	ld := derivativeFactors(nd, spacing[2], 2)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				v := coeff.At(i, j, k) * inverseHelmholtz(real(lr[i]+lc[j]+ld[k]), opts.Kappa)
+				coeff.Set(i, j, k, v)
+			}
+		}
+	}
+
+	phi := backTransform3(coeff, func(i, j, k int) complex128 { return 1.0 })
+	var grad [3]*Mat3
+	if opts.Gradient {
+		fr := derivativeFactors(nr, spacing[0], 1)
+		fc := derivativeFactors(nc, spacing[1], 1)
+		fd := derivativeFactors(nd, spacing[2], 1)
+		grad[0] = backTransform3(coeff, func(i, j, k int) complex128 { return fr[i] })
+		grad[1] = backTransform3(coeff, func(i, j, k int) complex128 { return fc[j] })
+		grad[2] = backTransform3(coeff, func(i, j, k int) complex128 { return fd[k] })
+	}
+	return phi, grad
+}
+
+// allPeriodic returns true if all boundary conditions are periodic
+func allPeriodic(bcs []Boundary) bool {
+	for _, bc := range bcs {
+		if bc != Periodic {
+			return false
+		}
+	}
+	return true
+}
+
+// forEachLine calls fn with the start index and the stride of each line along the given
+// axis of an array with the given shape. The shape is given in memory order (e.g.
+// {nr, nc} or {nd, nr, nc})
+func forEachLine(shape []int, axis int, fn func(start, stride int)) {
+	stride := 1
+	for i := axis + 1; i < len(shape); i++ {
+		stride *= shape[i]
+	}
+	for start := 0; start < prod(shape); start++ {
+		if (start/stride)%shape[axis] == 0 {
+			fn(start, stride)
+		}
+	}
+}
+
+// complexLines applies op in-place to each line along the given axis
+func complexLines(data []complex128, shape []int, axis int, op func(line []complex128) []complex128) {
+	buf := make([]complex128, shape[axis])
+	forEachLine(shape, axis, func(start, stride int) {
+		for i := range buf {
+			buf[i] = data[start+i*stride]
+		}
+		op(buf)
+		for i, v := range buf {
+			data[start+i*stride] = v
+		}
+	})
+}
+
+// realLines applies op in-place to each line along the given axis
+func realLines(data []float64, shape []int, axis int, op func(line []float64) []float64) {
+	buf := make([]float64, shape[axis])
+	forEachLine(shape, axis, func(start, stride int) {
+		transformStrided(data, buf, start, stride, op)
+	})
+}
+
+// poissonAxis holds the boundary condition, the number of grid points and the spacing
+// along an axis
+type poissonAxis struct {
+	bc Boundary
+	n  int
+	d  float64
+}
+
+// kind returns the transform that diagonalizes the Laplacian for non-periodic axes
+func (a poissonAxis) kind() TrigType {
+	if a.bc == Dirichlet {
+		return DST1
+	}
+	return DCT1
+}
+
+// waveNumber returns the wave number of mode k for Dirichlet and Neumann boundaries
+func (a poissonAxis) waveNumber(k int) float64 {
+	if a.bc == Dirichlet {
+		return math.Pi * float64(k+1) / (float64(a.n+1) * a.d)
+	}
+	return math.Pi * float64(k) / (float64(a.n-1) * a.d)
+}
+
+// eigenvalues returns the eigenvalues of the second derivative for each mode
+func (a poissonAxis) eigenvalues() []float64 {
+	lap := make([]float64, a.n)
+	if a.bc == Periodic {
+		for i, v := range derivativeFactors(a.n, a.d, 2) {
+			lap[i] = real(v)
+		}
+		return lap
+	}
+	for k := range lap {
+		q := a.waveNumber(k)
+		lap[k] = -q * q
+	}
+	return lap
+}
+
+// derivative returns a function that maps the DST-I (Dirichlet) or DCT-I (Neumann)
+// coefficients of a line to the derivative of the line in real space. The derivative of
+// a sine series is a cosine series evaluated on the interior points of a grid that is
+// extended by one point at each end (a DCT-I of length n+2), and the derivative of a
+// cosine series is a sine series that vanishes at the boundary points (a DST-I of
+// length n-2)
+func (a poissonAxis) derivative() func(line []float64) []float64 {
+	if a.bc == Dirichlet {
+		buf := make([]float64, a.n+2)
+		dtt := NewDTT1(a.n+2, DCT1)
+		return func(line []float64) []float64 {
+			buf[0] = 0.0
+			buf[a.n+1] = 0.0
+			for k, v := range line {
+				buf[k+1] = v * a.waveNumber(k)
+			}
+			dtt.Inverse(buf)
+			copy(line, buf[1:a.n+1])
+			return line
+		}
+	}
+	if a.n < 3 {
+		return func(line []float64) []float64 {
+			for i := range line {
+				line[i] = 0.0
+			}
+			return line
+		}
+	}
+	buf := make([]float64, a.n-2)
+	dtt := NewDTT1(a.n-2, DST1)
+	return func(line []float64) []float64 {
+		for k := 1; k < a.n-1; k++ {
+			buf[k-1] = -line[k] * a.waveNumber(k)
+		}
+		dtt.Inverse(buf)
+		line[0] = 0.0
+		line[a.n-1] = 0.0
+		copy(line[1:a.n-1], buf)
+		return line
+	}
+}
+
+// trapezoidWeights returns the weight of each grid point when computing the mean of a
+// field, where the boundary points of axes with Neumann boundary conditions have half
+// weight
+func trapezoidWeights(shape []int, axes []poissonAxis) []float64 {
+	weights := make([]float64, prod(shape))
+	for i := range weights {
+		weights[i] = 1.0
+	}
+	for i, a := range axes {
+		if a.bc != Neumann {
+			continue
+		}
+		forEachLine(shape, i, func(start, stride int) {
+			weights[start] *= 0.5
+			weights[start+(a.n-1)*stride] *= 0.5
+		})
+	}
+	return weights
+}
+
+// poissonBounded solves the Poisson equation (or the Helmholtz equation) with the
+// boundary condition and the spacing given along each axis of an array with the given
+// shape (in memory order). The solution is expanded in sine (Dirichlet), cosine (Neumann)
+// and Fourier (periodic) modes, which diagonalize the Laplacian. The second return value
+// holds the derivative along each axis if opts.Gradient is true.
+func poissonBounded(src []float64, shape []int, bcs []Boundary, spacing []float64, opts PoissonOptions) ([]float64, [][]float64) {
+	if len(src) != prod(shape) {
+		panic("poisson: Inconsistent size of the source")
+	}
+	axes := make([]poissonAxis, len(shape))
+	hasZeroMode := true
+	for i := range shape {
+		axes[i] = poissonAxis{bc: bcs[i], n: shape[i], d: spacing[i]}
+		if bcs[i] == Dirichlet {
+			hasZeroMode = false
+		}
+	}
+	if hasZeroMode {
+		opts.checkCompatible(src, trapezoidWeights(shape, axes))
+	}
+
+	// Real transforms along the non-periodic axes followed by FFTs along the periodic axes
+	data := make([]float64, len(src))
+	copy(data, src)
+	for i, a := range axes {
+		if a.bc != Periodic {
+			realLines(data, shape, i, NewDTT1(a.n, a.kind()).Transform)
+		}
+	}
+	coeff := ToComplex(data)
+	nPeriodic := 1
+	for i, a := range axes {
+		if a.bc == Periodic {
+			complexLines(coeff, shape, i, NewCFFT(a.n).FFT)
+			nPeriodic *= a.n
+		}
+	}
+
+	lap := make([]float64, len(coeff))
+	for i, a := range axes {
+		eig := a.eigenvalues()
+		forEachLine(shape, i, func(start, stride int) {
+			for k, v := range eig {
+				lap[start+k*stride] += v
+			}
+		})
+	}
+	for i := range coeff {
+		coeff[i] *= inverseHelmholtz(lap[i], opts.Kappa)
+	}
+
+	// back transforms the coefficients to real space. Along derivAxis, the derivative is
+	// calculated
+	back := func(coeff []complex128, derivAxis int) []float64 {
+		work := make([]complex128, len(coeff))
+		copy(work, coeff)
+		for i, a := range axes {
+			if a.bc != Periodic {
+				continue
+			}
+			if i == derivAxis {
+				factors := derivativeFactors(a.n, a.d, 1)
+				forEachLine(shape, i, func(start, stride int) {
+					for k, f := range factors {
+						work[start+k*stride] *= f
+					}
+				})
+			}
+			complexLines(work, shape, i, NewCFFT(a.n).IFFT)
+		}
+		res := realScaled(work, nPeriodic)
+		for i, a := range axes {
+			if a.bc == Periodic {
+				continue
+			}
+			if i == derivAxis {
+				realLines(res, shape, i, a.derivative())
+			} else {
+				realLines(res, shape, i, NewDTT1(a.n, a.kind()).Inverse)
+			}
+		}
+		return res
+	}
+
+	phi := back(coeff, -1)
+	grad := make([][]float64, len(shape))
+	if opts.Gradient {
+		for i := range grad {
+			grad[i] = back(coeff, i)
+		}
+	}
+	return phi, grad
+}
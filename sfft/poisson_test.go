@@ -0,0 +1,207 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestPoisson2(t *testing.T) {
+	nr, nc := 16, 12
+	spacing := []float64{0.25, 0.5}
+	ky := 2.0 * math.Pi / (float64(nr) * spacing[0])
+	kx := 2.0 * 2.0 * math.Pi / (float64(nc) * spacing[1])
+
+	for _, kappa := range []float64{0.0, 1.5} {
+		phi := make([]float64, nr*nc)
+		src := make([]float64, nr*nc)
+		dy := make([]float64, nr*nc)
+		dx := make([]float64, nr*nc)
+		offset := 0.0
+		if kappa != 0.0 {
+			// A constant is only determined for the Helmholtz equation
+			offset = 2.0
+		}
+		for i := 0; i < nr; i++ {
+			for j := 0; j < nc; j++ {
+				y := float64(i) * spacing[0]
+				x := float64(j) * spacing[1]
+				v := math.Sin(ky*y) * math.Cos(kx*x)
+				phi[i*nc+j] = offset + v
+				src[i*nc+j] = -(kx*kx+ky*ky+kappa*kappa)*v - kappa*kappa*offset
+				dy[i*nc+j] = ky * math.Cos(ky*y) * math.Cos(kx*x)
+				dx[i*nc+j] = -kx * math.Sin(ky*y) * math.Sin(kx*x)
+			}
+		}
+
+		res, grad := Poisson2(src, nr, nc, PoissonOptions{Spacing: spacing, Kappa: kappa, Gradient: true})
+		if !floats.EqualApprox(res, phi, 1e-10) {
+			t.Errorf("kappa=%f: Unexpected potential", kappa)
+		}
+		if !floats.EqualApprox(grad[0], dy, 1e-10) || !floats.EqualApprox(grad[1], dx, 1e-10) {
+			t.Errorf("kappa=%f: Unexpected gradient", kappa)
+		}
+	}
+}
+
+func TestPoisson3(t *testing.T) {
+	nr, nc, nd := 8, 4, 6
+	k := [3]float64{2.0 * math.Pi / 8.0, 2.0 * math.Pi / 4.0, 2.0 * 2.0 * math.Pi / 6.0}
+	for _, kappa := range []float64{0.0, 0.5} {
+		phi := NewMat3(nr, nc, nd, nil)
+		src := NewMat3(nr, nc, nd, nil)
+		k2 := k[0]*k[0] + k[1]*k[1] + k[2]*k[2] + kappa*kappa
+		for i := 0; i < nr; i++ {
+			for j := 0; j < nc; j++ {
+				for l := 0; l < nd; l++ {
+					v := math.Cos(k[0]*float64(i)) * math.Sin(k[1]*float64(j)) * math.Cos(k[2]*float64(l))
+					phi.Set(i, j, l, v)
+					src.Set(i, j, l, -k2*v)
+				}
+			}
+		}
+
+		res, grad := Poisson3(src, PoissonOptions{Kappa: kappa})
+		if !floats.EqualApprox(res.Data, phi.Data, 1e-10) {
+			t.Errorf("kappa=%f: Unexpected potential", kappa)
+		}
+		if grad[0] != nil || grad[1] != nil || grad[2] != nil {
+			t.Errorf("kappa=%f: Gradient should not be calculated", kappa)
+		}
+	}
+}
+
+func TestPoissonGradient3(t *testing.T) {
+	nr, nc, nd := 4, 4, 8
+	src := NewMat3(nr, nc, nd, nil)
+	k := 2.0 * math.Pi / 8.0
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for l := 0; l < nd; l++ {
+				src.Set(i, j, l, -k*k*math.Sin(k*float64(l)))
+			}
+		}
+	}
+	_, grad := Poisson3(src, PoissonOptions{Gradient: true})
+	for l := 0; l < nd; l++ {
+		expect := k * math.Cos(k*float64(l))
+		if math.Abs(grad[2].At(1, 2, l)-expect) > 1e-10 {
+			t.Errorf("Expected %f got %f", expect, grad[2].At(1, 2, l))
+		}
+		if math.Abs(grad[0].At(1, 2, l)) > 1e-10 || math.Abs(grad[1].At(1, 2, l)) > 1e-10 {
+			t.Errorf("Expected zero gradient along rows and columns")
+		}
+	}
+}
+
+func TestPoissonIncompatibleSource(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Poisson2 should panic when the source has non-zero mean")
+		}
+	}()
+	src := []float64{1.0, 2.0, 3.0, 4.0}
+	Poisson2(src, 2, 2, PoissonOptions{})
+}
+
+func TestPoisson2Boundaries(t *testing.T) {
+	nr, nc := 10, 7
+	spacing := []float64{0.3, 0.5}
+	for _, bc := range []Boundary{Dirichlet, Neumann} {
+		for _, kappa := range []float64{0.0, 1.2} {
+			// Coordinates of the grid points and the domain lengths
+			pos := func(i int, d float64) float64 { return float64(i) * d }
+			lr := float64(nr-1) * spacing[0]
+			lc := float64(nc-1) * spacing[1]
+			basis := math.Cos
+			dBasis := func(x float64) float64 { return -math.Sin(x) }
+			if bc == Dirichlet {
+				pos = func(i int, d float64) float64 { return float64(i+1) * d }
+				lr = float64(nr+1) * spacing[0]
+				lc = float64(nc+1) * spacing[1]
+				basis = math.Sin
+				dBasis = math.Cos
+			}
+			qr := math.Pi / lr
+			qc := 2.0 * math.Pi / lc
+
+			phi := make([]float64, nr*nc)
+			src := make([]float64, nr*nc)
+			dy := make([]float64, nr*nc)
+			dx := make([]float64, nr*nc)
+			for i := 0; i < nr; i++ {
+				for j := 0; j < nc; j++ {
+					y := pos(i, spacing[0])
+					x := pos(j, spacing[1])
+					idx := i*nc + j
+					phi[idx] = basis(qr*y) * basis(qc*x)
+					src[idx] = -(qr*qr + qc*qc + kappa*kappa) * phi[idx]
+					dy[idx] = qr * dBasis(qr*y) * basis(qc*x)
+					dx[idx] = qc * basis(qr*y) * dBasis(qc*x)
+				}
+			}
+
+			opts := PoissonOptions{Spacing: spacing, Kappa: kappa, Gradient: true, Boundary: []Boundary{bc}}
+			res, grad := Poisson2(src, nr, nc, opts)
+			if !floats.EqualApprox(res, phi, 1e-10) {
+				t.Errorf("Boundary %d, kappa=%f: Unexpected potential", bc, kappa)
+			}
+			if !floats.EqualApprox(grad[0], dy, 1e-10) || !floats.EqualApprox(grad[1], dx, 1e-10) {
+				t.Errorf("Boundary %d, kappa=%f: Unexpected gradient", bc, kappa)
+			}
+		}
+	}
+}
+
+func TestPoisson3MixedBoundaries(t *testing.T) {
+	nr, nc, nd := 6, 5, 8
+	spacing := []float64{0.5, 1.0, 0.25}
+	qr := math.Pi / (float64(nr+1) * spacing[0])
+	qc := 2.0 * math.Pi / (float64(nc-1) * spacing[1])
+	qd := 2.0 * math.Pi / (float64(nd) * spacing[2])
+	phi := NewMat3(nr, nc, nd, nil)
+	src := NewMat3(nr, nc, nd, nil)
+	var grad [3]*Mat3
+	for i := range grad {
+		grad[i] = NewMat3(nr, nc, nd, nil)
+	}
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				y := float64(i+1) * spacing[0]
+				x := float64(j) * spacing[1]
+				z := float64(k) * spacing[2]
+				v := math.Sin(qr*y) * math.Cos(qc*x) * math.Sin(qd*z)
+				phi.Set(i, j, k, v)
+				src.Set(i, j, k, -(qr*qr+qc*qc+qd*qd)*v)
+				grad[0].Set(i, j, k, qr*math.Cos(qr*y)*math.Cos(qc*x)*math.Sin(qd*z))
+				grad[1].Set(i, j, k, -qc*math.Sin(qr*y)*math.Sin(qc*x)*math.Sin(qd*z))
+				grad[2].Set(i, j, k, qd*math.Sin(qr*y)*math.Cos(qc*x)*math.Cos(qd*z))
+			}
+		}
+	}
+
+	opts := PoissonOptions{Spacing: spacing, Gradient: true, Boundary: []Boundary{Dirichlet, Neumann, Periodic}}
+	res, resGrad := Poisson3(src, opts)
+	if !floats.EqualApprox(res.Data, phi.Data, 1e-10) {
+		t.Errorf("Unexpected potential")
+	}
+	for i := range grad {
+		if !floats.EqualApprox(resGrad[i].Data, grad[i].Data, 1e-10) {
+			t.Errorf("Axis %d: Unexpected gradient", i)
+		}
+	}
+}
+
+func TestPoissonNeumannIncompatibleSource(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Poisson2 should panic when the source has non-zero mean")
+		}
+	}()
+
+	// The trapezoidal mean of the source is non-zero, even though the mean is zero
+	src := []float64{-2.0, 1.0, 1.0, -2.0, 1.0, 1.0}
+	Poisson2(src, 2, 3, PoissonOptions{Boundary: []Boundary{Periodic, Neumann}})
+}
@@ -0,0 +1,196 @@
+package sfft
+
+import (
+	"math/cmplx"
+)
+
+// Detrend specifies how each segment is detrended before it is Fourier transformed
+type Detrend int
+
+const (
+	// DetrendNone leaves the segments untouched
+	DetrendNone Detrend = iota
+
+	// DetrendConstant subtracts the mean of each segment
+	DetrendConstant
+
+	// DetrendLinear subtracts the least squares straight line fitted to each segment
+	DetrendLinear
+)
+
+// WindowFunc is a function that multiplies seq by a window in-place and returns it.
+// The window functions in Gonum's dsp/window package (e.g. window.Hann) can be used
+// directly
+type WindowFunc func(seq []float64) []float64
+
+// PSDConfig holds the parameters used when estimating power spectral densities
+type PSDConfig struct {
+	// SampleRate is the number of samples per unit time. If zero, a sample rate of 1 is used
+	SampleRate float64
+
+	// SegmentLength is the number of samples in each segment. If zero, 256 is used (or the
+	// length of the signal if it is shorter). It is ignored by Periodogram
+	SegmentLength int
+
+	// Overlap is the number of samples shared by two consecutive segments. It has to be
+	// smaller than SegmentLength. It is ignored by Periodogram
+	Overlap int
+
+	// Window is applied to every segment. If nil, a rectangular window is used
+	Window WindowFunc
+
+	// Detrend specifies how each segment is detrended
+	Detrend Detrend
+
+	// TwoSided returns the full spectrum ordered in the same way as the coefficients of
+	// a complex FFT (see FFT1.Freq). If false, the one-sided spectrum containing only the
+	// non-negative frequencies is returned, where the power of the negative frequencies
+	// has been folded into the positive ones
+	TwoSided bool
+}
+
+// sampleRate returns the sample rate, replacing zero with the default value
+func (c PSDConfig) sampleRate() float64 {
+	if c.SampleRate == 0.0 {
+		return 1.0
+	}
+	return c.SampleRate
+}
+
+// Periodogram estimates the power spectral density of data using a single segment
+// covering the full signal. The first return value is the frequencies (in units of
+// the sample rate) and the second is the power spectral density in units of
+// data^2/frequency. SegmentLength and Overlap in conf are ignored.
+func Periodogram(data []float64, conf PSDConfig) ([]float64, []float64) {
+	conf.SegmentLength = len(data)
+	conf.Overlap = 0
+	return Welch(data, conf)
+}
+
+// Welch estimates the power spectral density of data using Welch's method. The signal
+// is divided into overlapping segments which are detrended, windowed and Fourier
+// transformed, and the squared magnitudes are averaged. The first return value is the
+// frequencies (in units of the sample rate) and the second is the power spectral density
+// in units of data^2/frequency.
+func Welch(data []float64, conf PSDConfig) ([]float64, []float64) {
+	segLen := conf.SegmentLength
+	if segLen == 0 {
+		segLen = 256
+	}
+	if segLen > len(data) {
+		segLen = len(data)
+	}
+	if segLen < 1 {
+		panic("psd: The signal has to contain at least one sample")
+	}
+	if conf.Overlap < 0 || conf.Overlap >= segLen {
+		panic("psd: Overlap has to be non-negative and smaller than the segment length")
+	}
+	step := segLen - conf.Overlap
+
+	win := make([]float64, segLen)
+	for i := range win {
+		win[i] = 1.0
+	}
+	if conf.Window != nil {
+		conf.Window(win)
+	}
+	winPow := 0.0
+	for _, w := range win {
+		winPow += w * w
+	}
+
+	ft := NewFFT1(segLen)
+	nHalf := segLen/2 + 1
+	power := make([]float64, nHalf)
+	segment := make([]float64, segLen)
+	numSeg := 0
+	for start := 0; start+segLen <= len(data); start += step {
+		copy(segment, data[start:start+segLen])
+		detrend(segment, conf.Detrend)
+		for i := range segment {
+			segment[i] *= win[i]
+		}
+		coeff := ft.FFT(segment)
+		for i := range coeff {
+			v := cmplx.Abs(coeff[i])
+			power[i] += v * v
+		}
+		numSeg++
+	}
+
+	fs := conf.sampleRate()
+	scale := 1.0 / (fs * winPow * float64(numSeg))
+	for i := range power {
+		power[i] *= scale
+	}
+
+	if conf.TwoSided {
+		freq := make([]float64, segLen)
+		psd := make([]float64, segLen)
+		for i := range psd {
+			freq[i] = fs * ft.Freq(i)
+			if i < nHalf {
+				psd[i] = power[i]
+			} else {
+				psd[i] = power[segLen-i]
+			}
+		}
+		return freq, psd
+	}
+
+	freq := make([]float64, nHalf)
+	for i := range power {
+		freq[i] = fs * ft.Freq(i)
+
+		// The zero frequency and the Nyquist frequency (even lengths) has no negative
+		// counterpart
+		if i == 0 || (segLen%2 == 0 && i == segLen/2) {
+			continue
+		}
+		power[i] *= 2.0
+	}
+	return freq, power
+}
+
+// detrend removes the trend specified by method from data in-place
+func detrend(data []float64, method Detrend) {
+	switch method {
+	case DetrendNone:
+		return
+	case DetrendConstant:
+		mean := 0.0
+		for _, v := range data {
+			mean += v
+		}
+		mean /= float64(len(data))
+		for i := range data {
+			data[i] -= mean
+		}
+	case DetrendLinear:
+		n := float64(len(data))
+		xMean := (n - 1.0) / 2.0
+		yMean := 0.0
+		for _, v := range data {
+			yMean += v
+		}
+		yMean /= n
+
+		cov := 0.0
+		varX := 0.0
+		for i, v := range data {
+			dx := float64(i) - xMean
+			cov += dx * (v - yMean)
+			varX += dx * dx
+		}
+		slope := 0.0
+		if varX > 0.0 {
+			slope = cov / varX
+		}
+		for i := range data {
+			data[i] -= yMean + slope*(float64(i)-xMean)
+		}
+	default:
+		panic("psd: Unknown detrend method")
+	}
+}
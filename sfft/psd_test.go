@@ -0,0 +1,114 @@
+package sfft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/window"
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestWelchWhiteNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	sigma := 2.0
+	fs := 100.0
+	data := make([]float64, 1<<16)
+	for i := range data {
+		data[i] = sigma * rng.NormFloat64()
+	}
+
+	for i, conf := range []PSDConfig{
+		{SampleRate: fs, SegmentLength: 256},
+		{SampleRate: fs, SegmentLength: 256, Overlap: 128, Window: window.Hann},
+		{SampleRate: fs, SegmentLength: 128, Overlap: 64, Window: window.Hann, Detrend: DetrendLinear},
+	} {
+		freq, psd := Welch(data, conf)
+		if len(freq) != conf.SegmentLength/2+1 || len(psd) != len(freq) {
+			t.Errorf("Test #%d: Unexpected length. Got %d and %d", i, len(freq), len(psd))
+			continue
+		}
+		if math.Abs(freq[len(freq)-1]-fs/2.0) > 1e-10 {
+			t.Errorf("Test #%d: Expected last frequency %f got %f", i, fs/2.0, freq[len(freq)-1])
+		}
+
+		// White noise has a flat one-sided spectrum equal to 2*sigma^2/fs
+		level := floats.Sum(psd[1:len(psd)-1]) / float64(len(psd)-2)
+		expect := 2.0 * sigma * sigma / fs
+		if math.Abs(level-expect) > 0.05*expect {
+			t.Errorf("Test #%d: Expected PSD level %f got %f", i, expect, level)
+		}
+	}
+}
+
+func TestPeriodogramSinusoid(t *testing.T) {
+	fs := 64.0
+	amp := 3.0
+	f0 := 8.0
+	data := make([]float64, 512)
+	for i := range data {
+		data[i] = amp*math.Sin(2.0*math.Pi*f0*float64(i)/fs) + 1.0
+	}
+
+	for i, conf := range []PSDConfig{
+		{SampleRate: fs, Detrend: DetrendConstant},
+		{SampleRate: fs, Detrend: DetrendConstant, Window: window.Hann},
+	} {
+		freq, psd := Periodogram(data, conf)
+		df := freq[1] - freq[0]
+		power := floats.Sum(psd) * df
+		expect := amp * amp / 2.0
+		if math.Abs(power-expect) > 1e-2*expect {
+			t.Errorf("Test #%d: Expected total power %f got %f", i, expect, power)
+		}
+
+		peak := floats.MaxIdx(psd)
+		if math.Abs(freq[peak]-f0) > 1e-10 {
+			t.Errorf("Test #%d: Expected peak at %f got %f", i, f0, freq[peak])
+		}
+
+		if psd[0] > 1e-10 {
+			t.Errorf("Test #%d: Mean was not removed. DC component %e", i, psd[0])
+		}
+	}
+}
+
+func TestWelchTwoSided(t *testing.T) {
+	data := []float64{1.0, -2.0, 3.0, 0.5, 2.0, -1.0, 4.0, 2.5, 0.0}
+	for i, segLen := range []int{4, 5} {
+		conf := PSDConfig{SampleRate: 2.0, SegmentLength: segLen, Overlap: 2}
+		_, oneSided := Welch(data, conf)
+		conf.TwoSided = true
+		freq, twoSided := Welch(data, conf)
+
+		if len(twoSided) != segLen {
+			t.Errorf("Test #%d: Expected length %d got %d", i, segLen, len(twoSided))
+		}
+
+		// Both estimates have to contain the same total power
+		if math.Abs(floats.Sum(oneSided)-floats.Sum(twoSided)) > 1e-10 {
+			t.Errorf("Test #%d: Total power differ. One-sided %f two-sided %f", i, floats.Sum(oneSided), floats.Sum(twoSided))
+		}
+
+		for j := range freq {
+			if math.Abs(freq[j]-2.0*float64(j)/float64(segLen)) > 1e-10 && math.Abs(freq[j]-2.0*float64(j-segLen)/float64(segLen)) > 1e-10 {
+				t.Errorf("Test #%d: Unexpected frequency %f at index %d", i, freq[j], j)
+			}
+		}
+	}
+}
+
+func TestDetrend(t *testing.T) {
+	data := []float64{1.0, 3.0, 5.0, 7.0, 9.0}
+	detrend(data, DetrendLinear)
+	if !floats.EqualApprox(data, make([]float64, 5), 1e-10) {
+		t.Errorf("Linear trend was not removed. Got %v", data)
+	}
+
+	data = []float64{1.0, 3.0, 5.0}
+	detrend(data, DetrendConstant)
+	expect := []float64{-2.0, 0.0, 2.0}
+	if !floats.EqualApprox(data, expect, 1e-10) {
+		t.Errorf("Expected %v got %v", expect, data)
+	}
+}
@@ -0,0 +1,147 @@
+package sfft
+
+import "math"
+
+// RadialOptions holds the parameters used when computing radial averages of spectra
+type RadialOptions struct {
+	// BinWidth is the width of the bins in units of frequency (e.g. inverse length when
+	// Spacing is given). If zero, the coarsest frequency resolution of the grid, 1/(n*d),
+	// is used
+	BinWidth float64
+
+	// Spacing is the grid spacing along each axis (rows, columns and depth). If nil,
+	// a spacing of 1.0 is used along all axes and the frequencies are in cycles per sample
+	Spacing []float64
+}
+
+// spacing returns the grid spacing along each of the dim axes
+func (r RadialOptions) spacing(dim int) []float64 {
+	if r.Spacing == nil {
+		spacing := make([]float64, dim)
+		for i := range spacing {
+			spacing[i] = 1.0
+		}
+		return spacing
+	}
+	if len(r.Spacing) != dim {
+		panic("radial: The length of Spacing has to match the number of dimensions")
+	}
+	return r.Spacing
+}
+
+// binWidth returns the bin width for a grid with the given dimensions and spacing
+func (r RadialOptions) binWidth(dims []int, spacing []float64) float64 {
+	if r.BinWidth > 0.0 {
+		return r.BinWidth
+	}
+	width := 0.0
+	for i, n := range dims {
+		width = math.Max(width, 1.0/(float64(n)*spacing[i]))
+	}
+	return width
+}
+
+// RadialProfile holds a radially averaged quantity. Bin i contains all frequencies f
+// where |f - Centers[i]| <= BinWidth/2, and the first bin is centered at zero frequency.
+type RadialProfile struct {
+	// Centers is the frequency at the center of each bin
+	Centers []float64
+
+	// Mean is the average value in each bin. Empty bins have zero mean
+	Mean []float64
+
+	// Count is the number of frequencies in each bin. When a half spectrum is passed,
+	// the count includes the frequencies of the missing half
+	Count []int
+
+	// Std is the standard deviation of the values in each bin
+	Std []float64
+}
+
+// radialAccumulator accumulates weighted sums in radial bins
+type radialAccumulator struct {
+	width float64
+	sum   []float64
+	sumSq []float64
+	count []int
+}
+
+// add adds value to the bin corresponding to the radius r with the given weight
+func (a *radialAccumulator) add(r, value float64, weight int) {
+	bin := int(math.Floor(r/a.width + 0.5))
+	for len(a.sum) <= bin {
+		a.sum = append(a.sum, 0.0)
+		a.sumSq = append(a.sumSq, 0.0)
+		a.count = append(a.count, 0)
+	}
+	w := float64(weight)
+	a.sum[bin] += w * value
+	a.sumSq[bin] += w * value * value
+	a.count[bin] += weight
+}
+
+// profile returns the radial profile
+func (a *radialAccumulator) profile() RadialProfile {
+	n := len(a.sum)
+	p := RadialProfile{
+		Centers: make([]float64, n),
+		Mean:    make([]float64, n),
+		Count:   a.count,
+		Std:     make([]float64, n),
+	}
+	for i := range a.sum {
+		p.Centers[i] = float64(i) * a.width
+		if a.count[i] == 0 {
+			continue
+		}
+		c := float64(a.count[i])
+		p.Mean[i] = a.sum[i] / c
+		p.Std[i] = math.Sqrt(math.Max(a.sumSq[i]/c-p.Mean[i]*p.Mean[i], 0.0))
+	}
+	return p
+}
+
+// halfWeight returns the number of frequencies represented by column j of a spectrum
+// with nc columns of which ncStored are stored
+func halfWeight(j, nc, ncStored int) int {
+	if ncStored == nc || j == 0 || 2*j == nc {
+		return 1
+	}
+	return 2
+}
+
+// RadialAverage2 returns the radial average of a quantity defined on the frequency grid
+// of a 2D transform of an array with nr rows and nc columns (e.g. the power spectrum
+// |F|^2, which gives the structure factor S(k) up to normalization). data can either
+// hold the full spectrum in the layout of FFT2 (length nr*nc), or a half spectrum where
+// only the nc/2+1 non-negative column frequencies are stored (length nr*(nc/2+1)). The
+// missing half is assumed to be the mirror image of the stored half.
+func RadialAverage2(data []float64, nr, nc int, opts RadialOptions) RadialProfile {
+	ncStored := storedColumns(len(data), nr*nc, nr*(nc/2+1), nc)
+	spacing := opts.spacing(2)
+	acc := radialAccumulator{width: opts.binWidth([]int{nr, nc}, spacing)}
+	forEachFreq2(nr, nc, ncStored, func(idx int, fr, fc float64) {
+		fr /= spacing[0]
+		fc /= spacing[1]
+		acc.add(math.Sqrt(fr*fr+fc*fc), data[idx], halfWeight(idx%ncStored, nc, ncStored))
+	})
+	return acc.profile()
+}
+
+// RadialAverage3 returns the radial average of a quantity defined on the frequency grid
+// of a 3D transform of an nr x nc x nd array. data can either hold the full spectrum in
+// the layout of FFT3 (length nr*nc*nd), or a half spectrum where only the nc/2+1
+// non-negative column frequencies are stored (length nr*(nc/2+1)*nd). See also
+// RadialAverage2.
+func RadialAverage3(data []float64, nr, nc, nd int, opts RadialOptions) RadialProfile {
+	ncStored := storedColumns(len(data), nr*nc*nd, nr*(nc/2+1)*nd, nc)
+	spacing := opts.spacing(3)
+	acc := radialAccumulator{width: opts.binWidth([]int{nr, nc, nd}, spacing)}
+	forEachFreq3(nr, nc, nd, ncStored, func(idx int, fr, fc, fd float64) {
+		fr /= spacing[0]
+		fc /= spacing[1]
+		fd /= spacing[2]
+		acc.add(math.Sqrt(fr*fr+fc*fc+fd*fd), data[idx], halfWeight(idx%ncStored, nc, ncStored))
+	})
+	return acc.profile()
+}
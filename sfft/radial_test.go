@@ -0,0 +1,151 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// radialEqual returns true if the two profiles are equal within the tolerance
+func radialEqual(a, b RadialProfile, tol float64) bool {
+	if len(a.Count) != len(b.Count) {
+		return false
+	}
+	for i := range a.Count {
+		if a.Count[i] != b.Count[i] {
+			return false
+		}
+	}
+	return floats.EqualApprox(a.Centers, b.Centers, tol) && floats.EqualApprox(a.Mean, b.Mean, tol) && floats.EqualApprox(a.Std, b.Std, tol)
+}
+
+func TestRadialAverage2Small(t *testing.T) {
+	// 4x4 grid: the squared radii (in units of 1/4) are 0 (1), 1 (4), 2 (4), 4 (2), 5 (4)
+	// and 8 (1), where the number of frequencies is given in parenthesis. The radii are
+	// rounded to the nearest bin
+	nr, nc := 4, 4
+	data := make([]float64, nr*nc)
+	forEachFreq2(nr, nc, nc, func(idx int, fr, fc float64) {
+		data[idx] = 16.0 * (fr*fr + fc*fc)
+	})
+	p := RadialAverage2(data, nr, nc, RadialOptions{})
+
+	wantCount := []int{1, 8, 6, 1}
+	wantMean := []float64{0.0, 1.5, (2.0*4.0 + 4.0*5.0) / 6.0, 8.0}
+	if len(p.Count) != len(wantCount) {
+		t.Fatalf("Expected %d bins got %d", len(wantCount), len(p.Count))
+	}
+	for i := range wantCount {
+		if p.Count[i] != wantCount[i] || math.Abs(p.Mean[i]-wantMean[i]) > 1e-12 || math.Abs(p.Centers[i]-0.25*float64(i)) > 1e-12 {
+			t.Errorf("Bin %d: Expected center %f, count %d and mean %f got %f, %d and %f", i, 0.25*float64(i), wantCount[i], wantMean[i], p.Centers[i], p.Count[i], p.Mean[i])
+		}
+	}
+	if math.Abs(p.Std[1]-0.5) > 1e-12 || math.Abs(p.Std[2]-math.Sqrt((2.0*16.0+4.0*25.0)/6.0-wantMean[2]*wantMean[2])) > 1e-12 {
+		t.Errorf("Unexpected standard deviation %v", p.Std)
+	}
+}
+
+func TestRadialAverageHalfSpectrum(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	for _, nc := range []int{8, 9} {
+		nr, nd := 6, 5
+		data := make([]float64, nr*nc*nd)
+		for i := range data {
+			data[i] = rng.NormFloat64()
+		}
+
+		// 2D
+		ft2 := NewFFT2(nr, nc)
+		c2 := ft2.FFT(ToComplex(data[:nr*nc]))
+		full := make([]float64, nr*nc)
+		half := make([]float64, nr*(nc/2+1))
+		for i := 0; i < nr; i++ {
+			for j := 0; j < nc; j++ {
+				a := cmplx.Abs(c2[i*nc+j])
+				full[i*nc+j] = a * a
+				if j <= nc/2 {
+					half[i*(nc/2+1)+j] = a * a
+				}
+			}
+		}
+		opts := RadialOptions{Spacing: []float64{0.5, 2.0}, BinWidth: 0.1}
+		if !radialEqual(RadialAverage2(full, nr, nc, opts), RadialAverage2(half, nr, nc, opts), 1e-10) {
+			t.Errorf("nc=%d: Half and full 2D spectrum give different profiles", nc)
+		}
+
+		// 3D
+		ft3 := NewFFT3(nr, nc, nd)
+		c3 := ft3.FFT(ToComplex(data))
+		full = make([]float64, len(data))
+		half = make([]float64, nr*(nc/2+1)*nd)
+		for k := 0; k < nd; k++ {
+			for i := 0; i < nr; i++ {
+				for j := 0; j < nc; j++ {
+					a := cmplx.Abs(c3[k*nr*nc+i*nc+j])
+					full[k*nr*nc+i*nc+j] = a * a
+					if j <= nc/2 {
+						half[k*nr*(nc/2+1)+i*(nc/2+1)+j] = a * a
+					}
+				}
+			}
+		}
+		p := RadialAverage3(full, nr, nc, nd, RadialOptions{})
+		if !radialEqual(p, RadialAverage3(half, nr, nc, nd, RadialOptions{}), 1e-10) {
+			t.Errorf("nc=%d: Half and full 3D spectrum give different profiles", nc)
+		}
+
+		total := 0
+		for _, c := range p.Count {
+			total += c
+		}
+		if total != len(data) {
+			t.Errorf("nc=%d: Expected %d frequencies in total got %d", nc, len(data), total)
+		}
+	}
+}
+
+func TestRadialAverageIsotropic(t *testing.T) {
+	// The power spectrum of an isotropic Gaussian is a Gaussian in the radial frequency
+	n := 32
+	spacing := 0.25
+	sigma := 1.0
+	c := NewMat3(n, n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			for k := 0; k < n; k++ {
+				x := float64(i-n/2) * spacing
+				y := float64(j-n/2) * spacing
+				z := float64(k-n/2) * spacing
+				c.Set(i, j, k, math.Exp(-(x*x+y*y+z*z)/(2.0*sigma*sigma)))
+			}
+		}
+	}
+	coeff := NewFFT3(n, n, n).FFT(ToComplex(c.Data))
+	power := make([]float64, len(coeff))
+	for i, v := range coeff {
+		a := cmplx.Abs(v)
+		power[i] = a * a
+	}
+	p := RadialAverage3(power, n, n, n, RadialOptions{Spacing: []float64{spacing, spacing, spacing}})
+	if math.Abs(p.Centers[1]-1.0/(float64(n)*spacing)) > 1e-12 {
+		t.Errorf("Expected the default bin width to be the frequency resolution. Got %f", p.Centers[1])
+	}
+
+	// The continuous transform is (2*pi*sigma^2)^(3/2) exp(-2 pi^2 sigma^2 f^2) / spacing^3
+	// where f is the frequency in physical units. The domain is truncated at 4 sigma
+	expect := make([]float64, len(power))
+	forEachFreq3(n, n, n, n, func(idx int, fr, fc, fd float64) {
+		f2 := (fr*fr + fc*fc + fd*fd) / (spacing * spacing)
+		amp := math.Pow(2.0*math.Pi*sigma*sigma, 1.5) / math.Pow(spacing, 3) * math.Exp(-2.0*math.Pi*math.Pi*sigma*sigma*f2)
+		expect[idx] = amp * amp
+	})
+	pExpect := RadialAverage3(expect, n, n, n, RadialOptions{Spacing: []float64{spacing, spacing, spacing}})
+	for i := 0; i < 6; i++ {
+		if math.Abs(p.Mean[i]-pExpect.Mean[i]) > 1e-3*pExpect.Mean[0] {
+			t.Errorf("Bin %d: Expected %f got %f", i, pExpect.Mean[i], p.Mean[i])
+		}
+	}
+}
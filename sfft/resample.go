@@ -0,0 +1,111 @@
+package sfft
+
+// resizeAxis changes the number of Fourier coefficients along one axis of a full
+// spectrum. The spectrum is stored as an array where the index of element (i_0, i_1, ...)
+// is sum_a i_a*stride_a, and the strides are given by the row-major ordering of shape
+// (i.e. the last axis is contiguous). If m is larger than the current size, zeros are
+// inserted at the highest frequencies, and if it is smaller the highest frequencies are
+// discarded. For even sizes, the Nyquist coefficient is split equally between the
+// positive and the negative frequency when padding, and the two are merged when
+// truncating, such that the spectrum of a real signal remains Hermitian.
+func resizeAxis(coeff []complex128, shape []int, axis int, m int) ([]complex128, []int) {
+	n := shape[axis]
+	newShape := make([]int, len(shape))
+	copy(newShape, shape)
+	newShape[axis] = m
+
+	inner := prod(shape[axis+1:])
+	outer := prod(shape[:axis])
+	res := make([]complex128, outer*m*inner)
+
+	// Number of coefficients with positive and negative frequencies that are shared
+	// by the two sizes (the zero frequency is counted among the positive ones)
+	small := n
+	if m < small {
+		small = m
+	}
+	numPos := (small + 1) / 2
+	numNeg := small - numPos
+	evenNyquist := small%2 == 0 && n != m
+
+	for o := 0; o < outer; o++ {
+		for in := 0; in < inner; in++ {
+			src := func(i int) int { return (o*n+i)*inner + in }
+			dst := func(i int) int { return (o*m+i)*inner + in }
+			for i := 0; i < numPos; i++ {
+				res[dst(i)] = coeff[src(i)]
+			}
+			for i := 1; i <= numNeg; i++ {
+				res[dst(m-i)] = coeff[src(n-i)]
+			}
+
+			if !evenNyquist {
+				continue
+			}
+			nyq := small / 2
+			if m > n {
+				// Split the Nyquist coefficient of the source
+				v := coeff[src(nyq)] / 2.0
+				res[dst(nyq)] = v
+				res[dst(m-nyq)] = v
+			} else {
+				// Merge the coefficients at +/- the new Nyquist frequency
+				res[dst(nyq)] = coeff[src(nyq)] + coeff[src(n-nyq)]
+			}
+		}
+	}
+	return res, newShape
+}
+
+// resampleComplex resamples a full spectrum with the given (row-major) shape to newShape
+func resampleComplex(coeff []complex128, shape []int, newShape []int) []complex128 {
+	for axis := range shape {
+		coeff, shape = resizeAxis(coeff, shape, axis, newShape[axis])
+	}
+	return coeff
+}
+
+// Resample1 resamples a periodic real sequence to length m by zero-padding or truncating
+// its spectrum. Upsampling a band-limited signal interpolates it exactly, and the mean
+// value is preserved
+func Resample1(data []float64, m int) []float64 {
+	n := len(data)
+	coeff := ToComplex(data)
+	NewCFFT(n).FFT(coeff)
+	coeff = resampleComplex(coeff, []int{n}, []int{m})
+	NewCFFT(m).IFFT(coeff)
+	return realScaled(coeff, n)
+}
+
+// Resample2 resamples a real periodic 2D array of size nr x nc (stored row-major) to
+// size mr x mc by zero-padding or truncating its spectrum. The mean is preserved
+func Resample2(data []float64, nr, nc, mr, mc int) []float64 {
+	if len(data) != nr*nc {
+		panic("resample: Inconsistent size in Resample2")
+	}
+	coeff := NewFFT2(nr, nc).FFT(ToComplex(data))
+	coeff = resampleComplex(coeff, []int{nr, nc}, []int{mr, mc})
+	NewFFT2(mr, mc).IFFT(coeff)
+	return realScaled(coeff, nr*nc)
+}
+
+// Resample3 resamples a real periodic 3D array to size mr x mc x md by zero-padding
+// or truncating its spectrum. The mean is preserved
+func Resample3(data *Mat3, mr, mc, md int) *Mat3 {
+	nr, nc, nd := data.Dims()
+	coeff := NewFFT3(nr, nc, nd).FFT(ToComplex(data.Data))
+
+	// The depth is the outermost axis in the underlying array
+	coeff = resampleComplex(coeff, []int{nd, nr, nc}, []int{md, mr, mc})
+	NewFFT3(mr, mc, md).IFFT(coeff)
+	return NewMat3(mr, mc, md, realScaled(coeff, nr*nc*nd))
+}
+
+// realScaled returns the real part of data divided by n
+func realScaled(data []complex128, n int) []float64 {
+	res := make([]float64, len(data))
+	for i := range data {
+		res[i] = real(data[i]) / float64(n)
+	}
+	return res
+}
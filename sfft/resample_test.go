@@ -0,0 +1,112 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// bandLimited1 evaluates a band-limited periodic function with period 8 at x
+func bandLimited1(x float64) float64 {
+	return 2.0 + math.Cos(2.0*math.Pi*3.0*x/8.0) + 0.5*math.Cos(math.Pi*x) + math.Sin(2.0*math.Pi*x/8.0)
+}
+
+func TestResample1(t *testing.T) {
+	for i, test := range []struct {
+		n int
+		m int
+	}{
+		{n: 8, m: 16},
+		{n: 8, m: 13},
+		{n: 16, m: 8},
+		{n: 13, m: 8},
+		{n: 8, m: 8},
+	} {
+		data := make([]float64, test.n)
+		for j := range data {
+			data[j] = bandLimited1(8.0 * float64(j) / float64(test.n))
+		}
+		res := Resample1(data, test.m)
+		expect := make([]float64, test.m)
+		for j := range expect {
+			expect[j] = bandLimited1(8.0 * float64(j) / float64(test.m))
+		}
+		if !floats.EqualApprox(res, expect, 1e-10) {
+			t.Errorf("Test #%d: Expected\n%v\ngot\n%v\n", i, expect, res)
+		}
+	}
+}
+
+func TestResample1Mean(t *testing.T) {
+	data := []float64{1.0, 5.0, -2.0, 3.0, 0.5, 4.0, 2.0}
+	mean := floats.Sum(data) / float64(len(data))
+	for _, m := range []int{3, 4, 10, 21} {
+		res := Resample1(data, m)
+		if math.Abs(floats.Sum(res)/float64(m)-mean) > 1e-10 {
+			t.Errorf("Mean not preserved for m=%d. Expected %f got %f", m, mean, floats.Sum(res)/float64(m))
+		}
+	}
+}
+
+// bandLimited2 evaluates a band-limited function with period 4 along x and 6 along y
+func bandLimited2(x, y float64) float64 {
+	return 1.0 + math.Cos(2.0*math.Pi*x/4.0)*math.Sin(2.0*math.Pi*y/6.0) + math.Cos(math.Pi*x)
+}
+
+func TestResample2(t *testing.T) {
+	nr, nc := 4, 6
+	data := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			data[i*nc+j] = bandLimited2(float64(i), float64(j))
+		}
+	}
+
+	mr, mc := 8, 9
+	res := Resample2(data, nr, nc, mr, mc)
+	for i := 0; i < mr; i++ {
+		for j := 0; j < mc; j++ {
+			expect := bandLimited2(4.0*float64(i)/float64(mr), 6.0*float64(j)/float64(mc))
+			if math.Abs(res[i*mc+j]-expect) > 1e-10 {
+				t.Errorf("Unexpected value at (%d, %d). Expected %f got %f", i, j, expect, res[i*mc+j])
+			}
+		}
+	}
+
+	back := Resample2(res, mr, mc, nr, nc)
+	if !floats.EqualApprox(back, data, 1e-10) {
+		t.Errorf("Downsampling did not recover the original data")
+	}
+}
+
+func TestResample3(t *testing.T) {
+	nr, nc, nd := 4, 3, 6
+	field := func(x, y, z float64) float64 {
+		return 0.5 + math.Cos(2.0*math.Pi*x/4.0)*math.Cos(2.0*math.Pi*z/6.0) + math.Sin(2.0*math.Pi*y/3.0) + math.Cos(math.Pi*z)
+	}
+	data := NewMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				data.Set(i, j, k, field(float64(i), float64(j), float64(k)))
+			}
+		}
+	}
+
+	res := Resample3(data, 8, 6, 12)
+	mr, mc, md := res.Dims()
+	if mr != 8 || mc != 6 || md != 12 {
+		t.Errorf("Unexpected dimensions (%d, %d, %d)", mr, mc, md)
+	}
+	for i := 0; i < mr; i++ {
+		for j := 0; j < mc; j++ {
+			for k := 0; k < md; k++ {
+				expect := field(float64(i)/2.0, float64(j)/2.0, float64(k)/2.0)
+				if math.Abs(res.At(i, j, k)-expect) > 1e-10 {
+					t.Errorf("Unexpected value at (%d, %d, %d). Expected %f got %f", i, j, k, expect, res.At(i, j, k))
+				}
+			}
+		}
+	}
+}
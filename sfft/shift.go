@@ -0,0 +1,131 @@
+package sfft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// shiftFactors returns the phase ramp that shifts a sequence of length n by d samples
+// when multiplied with its Fourier coefficients. For even n, the Nyquist coefficient
+// is multiplied by cos(pi*d) such that real sequences remain real
+func shiftFactors(n int, d float64) []complex128 {
+	factors := make([]complex128, n)
+	for i := range factors {
+		if n%2 == 0 && i == n/2 {
+			factors[i] = complex(math.Cos(math.Pi*d), 0.0)
+		} else {
+			factors[i] = cmplx.Exp(complex(0.0, -2.0*math.Pi*freqIndex(i, n)*d))
+		}
+	}
+	return factors
+}
+
+// shiftLine shifts the sequence in data by d samples using the passed transform
+func shiftLine(data []float64, d float64, ft *FFT1) {
+	coeff := ft.FFT(data)
+	factors := shiftFactors(len(data), d)
+	for i := range coeff {
+		coeff[i] *= factors[i]
+	}
+	ft.ft.Sequence(data, coeff)
+	for i := range data {
+		data[i] /= float64(len(data))
+	}
+}
+
+// FourierShift1 translates a periodic sequence by shift samples in-place, such that the
+// value at position i is moved to position i + shift. The shift does not need to be
+// an integer. The signal is interpolated by its Fourier series, thus band-limited
+// signals are shifted exactly
+func FourierShift1(data []float64, shift float64) []float64 {
+	shiftLine(data, shift, NewFFT1(len(data)))
+	return data
+}
+
+// FourierShift2 translates a periodic 2D array with nr rows and nc columns (stored row-major)
+// in-place. The value at (i, j) is moved to (i + dr, j + dc)
+func FourierShift2(data []float64, nr, nc int, dr, dc float64) []float64 {
+	if len(data) != nr*nc {
+		panic("shift: Inconsistent size in FourierShift2")
+	}
+	ft := NewFFT2(nr, nc)
+	coeff := ft.FFT(ToComplex(data))
+	fr := shiftFactors(nr, dr)
+	fc := shiftFactors(nc, dc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			coeff[i*nc+j] *= fr[i] * fc[j]
+		}
+	}
+	ft.IFFT(coeff)
+	for i := range data {
+		data[i] = real(coeff[i]) / float64(len(data))
+	}
+	return data
+}
+
+// FourierShift3 translates a periodic 3D array in-place. The value at (i, j, k) is moved
+// to (i + dr, j + dc, k + dd)
+func FourierShift3(data *Mat3, dr, dc, dd float64) *Mat3 {
+	nr, nc, nd := data.Dims()
+	ft := NewFFT3(nr, nc, nd)
+	coeff := NewCMat3(nr, nc, nd, ft.FFT(ToComplex(data.Data)))
+	fr := shiftFactors(nr, dr)
+	fc := shiftFactors(nc, dc)
+	fd := shiftFactors(nd, dd)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				coeff.Set(i, j, k, coeff.At(i, j, k)*fr[i]*fc[j]*fd[k])
+			}
+		}
+	}
+	ft.IFFT(coeff.Data)
+	for i := range data.Data {
+		data.Data[i] = real(coeff.Data[i]) / float64(len(data.Data))
+	}
+	return data
+}
+
+// Rotate2 rotates a periodic 2D array with nr rows and nc columns (stored row-major)
+// in-place by angle (in radians) around the point (nr/2, nc/2). With x being the column
+// index and y the row index, a point (x, y) is moved to (x*cos(angle) - y*sin(angle),
+// x*sin(angle) + y*cos(angle)) relative to the center. The rotation is decomposed into
+// three shears, each of which is carried out by shifting rows or columns with Fourier
+// phase ramps. Thus, no interpolation blur is introduced. For angles larger than
+// pi/2 in magnitude, the shears become large and content may wrap around the edges;
+// it is then better to first rotate by multiples of pi/2 by transposing the data.
+func Rotate2(data []float64, nr, nc int, angle float64) []float64 {
+	if len(data) != nr*nc {
+		panic("shift: Inconsistent size in Rotate2")
+	}
+	a := -math.Tan(angle / 2.0)
+	b := math.Sin(angle)
+	shearRows(data, nr, nc, a)
+	shearCols(data, nr, nc, b)
+	shearRows(data, nr, nc, a)
+	return data
+}
+
+// shearRows shifts row i by a*(i - nr/2) samples
+func shearRows(data []float64, nr, nc int, a float64) {
+	ft := NewFFT1(nc)
+	for i := 0; i < nr; i++ {
+		shiftLine(data[i*nc:(i+1)*nc], a*float64(i-nr/2), ft)
+	}
+}
+
+// shearCols shifts column j by b*(j - nc/2) samples
+func shearCols(data []float64, nr, nc int, b float64) {
+	ft := NewFFT1(nr)
+	col := make([]float64, nr)
+	for j := 0; j < nc; j++ {
+		for i := range col {
+			col[i] = data[i*nc+j]
+		}
+		shiftLine(col, b*float64(j-nc/2), ft)
+		for i := range col {
+			data[i*nc+j] = col[i]
+		}
+	}
+}
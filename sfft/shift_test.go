@@ -0,0 +1,109 @@
+package sfft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestShift1(t *testing.T) {
+	for _, n := range []int{16, 15} {
+		signal := func(x float64) float64 {
+			return 1.0 + math.Cos(2.0*math.Pi*2.0*x/float64(n)) + 0.3*math.Sin(2.0*math.Pi*5.0*x/float64(n))
+		}
+		for _, shift := range []float64{0.0, 0.25, -1.7, 3.0} {
+			data := make([]float64, n)
+			expect := make([]float64, n)
+			for i := range data {
+				data[i] = signal(float64(i))
+				expect[i] = signal(float64(i) - shift)
+			}
+			FourierShift1(data, shift)
+			if !floats.EqualApprox(data, expect, 1e-10) {
+				t.Errorf("n=%d shift=%f: Expected\n%v\ngot\n%v\n", n, shift, expect, data)
+			}
+		}
+	}
+}
+
+func TestShift1Nyquist(t *testing.T) {
+	data := []float64{1.0, -1.0, 1.0, -1.0}
+	FourierShift1(data, 0.5)
+	if !floats.EqualApprox(data, make([]float64, 4), 1e-10) {
+		t.Errorf("Half sample shift of the Nyquist mode should vanish. Got %v", data)
+	}
+}
+
+func TestShift2(t *testing.T) {
+	nr, nc := 8, 9
+	signal := func(y, x float64) float64 {
+		return math.Cos(2.0*math.Pi*(y/8.0+2.0*x/9.0)) + math.Sin(2.0*math.Pi*3.0*y/8.0)
+	}
+	dr, dc := 0.4, -2.3
+	data := make([]float64, nr*nc)
+	expect := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			data[i*nc+j] = signal(float64(i), float64(j))
+			expect[i*nc+j] = signal(float64(i)-dr, float64(j)-dc)
+		}
+	}
+	FourierShift2(data, nr, nc, dr, dc)
+	if !floats.EqualApprox(data, expect, 1e-10) {
+		t.Errorf("Unexpected result from FourierShift2")
+	}
+}
+
+func TestShift3(t *testing.T) {
+	nr, nc, nd := 4, 5, 6
+	signal := func(y, x, z float64) float64 {
+		return math.Cos(2.0*math.Pi*(y/4.0+x/5.0)) * math.Sin(2.0*math.Pi*2.0*z/6.0)
+	}
+	dr, dc, dd := 1.5, 0.2, -0.7
+	data := NewMat3(nr, nc, nd, nil)
+	expect := NewMat3(nr, nc, nd, nil)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			for k := 0; k < nd; k++ {
+				data.Set(i, j, k, signal(float64(i), float64(j), float64(k)))
+				expect.Set(i, j, k, signal(float64(i)-dr, float64(j)-dc, float64(k)-dd))
+			}
+		}
+	}
+	FourierShift3(data, dr, dc, dd)
+	if !floats.EqualApprox(data.Data, expect.Data, 1e-10) {
+		t.Errorf("Unexpected result from FourierShift3")
+	}
+}
+
+// gaussian2 returns a Gaussian blob centered at (y0, x0) on an nr x nc grid
+func gaussian2(nr, nc int, y0, x0, sigma float64) []float64 {
+	data := make([]float64, nr*nc)
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			dy := float64(i) - y0
+			dx := float64(j) - x0
+			data[i*nc+j] = math.Exp(-0.5 * (dx*dx + dy*dy) / (sigma * sigma))
+		}
+	}
+	return data
+}
+
+func TestRotate2(t *testing.T) {
+	nr, nc := 64, 64
+	sigma := 3.0
+	for _, angle := range []float64{0.0, 0.3, -0.8, math.Pi / 2.0} {
+		// Blob displaced from the center by (dy, dx)
+		dx, dy := 10.0, -4.0
+		data := gaussian2(nr, nc, float64(nr/2)+dy, float64(nc/2)+dx, sigma)
+		Rotate2(data, nr, nc, angle)
+
+		xRot := dx*math.Cos(angle) - dy*math.Sin(angle)
+		yRot := dx*math.Sin(angle) + dy*math.Cos(angle)
+		expect := gaussian2(nr, nc, float64(nr/2)+yRot, float64(nc/2)+xRot, sigma)
+		if !floats.EqualApprox(data, expect, 1e-6) {
+			t.Errorf("angle=%f: Rotated image does not match the analytic result", angle)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package sfft
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// STFT is a type for short-time Fourier transforms. The signal is divided into
+// overlapping frames which are multiplied by a window and Fourier transformed
+type STFT struct {
+	// Window is multiplied with every frame. Its length is the frame length
+	Window []float64
+
+	// Hop is the number of samples between the start of two consecutive frames
+	Hop int
+
+	// Center pads the signal with len(Window)/2 zeros at both ends, such that
+	// the first frame is centered at the first sample
+	Center bool
+
+	// RequireCOLA makes ISTFT panic if the window and hop violate the constant overlap-add
+	// condition (see COLA). By default, only the NOLA condition is required
+	RequireCOLA bool
+
+	nfft int
+	ft   *FFT1
+}
+
+// NewSTFT returns a new STFT. window is the window applied to each frame, hop is the
+// number of samples between two frames and nfft is the length of the transformed frames.
+// If nfft is larger than the length of the window, the frames are zero-padded. If nfft
+// is zero, it is set to the length of the window. By default the signal is padded such
+// that the frames are centered at the samples i*hop (see the Center field).
+func NewSTFT(window []float64, hop int, nfft int) *STFT {
+	if nfft == 0 {
+		nfft = len(window)
+	}
+	if len(window) == 0 || hop < 1 {
+		panic("stft: The window has to be non-empty and the hop has to be positive")
+	}
+	if nfft < len(window) {
+		panic("stft: nfft can not be smaller than the window length")
+	}
+	return &STFT{
+		Window: window,
+		Hop:    hop,
+		Center: true,
+		nfft:   nfft,
+		ft:     NewFFT1(nfft),
+	}
+}
+
+// pad returns the number of zeros added in front of the signal
+func (s *STFT) pad() int {
+	if s.Center {
+		return len(s.Window) / 2
+	}
+	return 0
+}
+
+// NumFrames returns the number of frames that is used for a signal of length n. The end
+// of the signal is zero-padded such that all samples are covered by at least one frame
+func (s *STFT) NumFrames(n int) int {
+	length := n + 2*s.pad()
+	if length <= len(s.Window) {
+		return 1
+	}
+	return 1 + (length-len(s.Window)+s.Hop-1)/s.Hop
+}
+
+// STFT performs the short-time Fourier transform of data. The result is a matrix where
+// each row is the (one-sided) spectrum of one frame. Thus, the number of rows is equal
+// to the number of frames and the number of columns is nfft/2+1.
+func (s *STFT) STFT(data []float64) *mat.CDense {
+	numFrames := s.NumFrames(len(data))
+	winLen := len(s.Window)
+	padded := make([]float64, (numFrames-1)*s.Hop+winLen)
+	copy(padded[s.pad():], data)
+
+	res := mat.NewCDense(numFrames, s.nfft/2+1, nil)
+	frame := make([]float64, s.nfft)
+	for i := 0; i < numFrames; i++ {
+		start := i * s.Hop
+		for j := range s.Window {
+			frame[j] = padded[start+j] * s.Window[j]
+		}
+		coeff := s.ft.FFT(frame)
+		for j := range coeff {
+			res.Set(i, j, coeff[j])
+		}
+	}
+	return res
+}
+
+// ISTFT reconstructs a signal of length n from the spectra returned by STFT using the
+// weighted overlap-add method, where the overlap-added frames are divided by the
+// overlap-added squared window. The reconstruction therefore requires the nonzero
+// overlap-add (NOLA) condition (see NOLA) rather than the stricter constant overlap-add
+// condition (see COLA). ISTFT panics if the window and hop violate the NOLA condition,
+// or if samples near the ends of the signal are not covered by a non-zero window value.
+// If RequireCOLA is set, ISTFT also panics if the COLA condition is violated, which is
+// useful when the spectra are modified and the frames should add up without weighting.
+func (s *STFT) ISTFT(spec *mat.CDense, n int) []float64 {
+	numFrames, nCols := spec.Dims()
+	if nCols != s.nfft/2+1 {
+		panic("stft: Inconsistent number of frequencies passed to ISTFT")
+	}
+	if !NOLA(s.Window, s.Hop, 1e-10) {
+		panic("stft: The window and hop violate the NOLA condition. The signal can not be reconstructed")
+	}
+	if s.RequireCOLA && !COLA(s.Window, s.Hop, 1e-10) {
+		panic("stft: The window and hop violate the COLA condition")
+	}
+	winLen := len(s.Window)
+	length := (numFrames-1)*s.Hop + winLen
+	pad := s.pad()
+	if pad+n > length {
+		panic("stft: Not enough frames to reconstruct a signal of the requested length")
+	}
+
+	signal := make([]float64, length)
+	winSum := make([]float64, length)
+	coeff := make([]complex128, nCols)
+	frame := make([]float64, s.nfft)
+	for i := 0; i < numFrames; i++ {
+		for j := range coeff {
+			coeff[j] = spec.At(i, j)
+		}
+		s.ft.ft.Sequence(frame, coeff)
+		start := i * s.Hop
+		for j, w := range s.Window {
+			signal[start+j] += w * frame[j] / float64(s.nfft)
+			winSum[start+j] += w * w
+		}
+	}
+
+	res := make([]float64, n)
+	for i := range res {
+		if winSum[pad+i] < 1e-10 {
+			panic("stft: The overlap-added squared window vanishes. The signal can not be reconstructed")
+		}
+		res[i] = signal[pad+i] / winSum[pad+i]
+	}
+	return res
+}
+
+// Freq returns the frequency corresponding to column i of the matrix returned by STFT.
+// The sample spacing is assumed to be 1.0
+func (s *STFT) Freq(i int) float64 {
+	return s.ft.Freq(i)
+}
+
+// Time returns the index of the sample at the center of frame i
+func (s *STFT) Time(i int) float64 {
+	return float64(i*s.Hop-s.pad()) + float64(len(s.Window)-1)/2.0
+}
+
+// COLA checks if window and hop satisfies the constant overlap-add condition, that is
+// the sum of the windows shifted by multiples of hop is constant within tol. When the
+// condition holds, the windowed frames of a signal sum up to a scaled copy of the signal
+// without any normalization. ISTFT normalizes by the squared window and only requires
+// the weaker NOLA condition, unless STFT.RequireCOLA is set
+func COLA(window []float64, hop int, tol float64) bool {
+	if hop < 1 {
+		panic("stft: The hop has to be positive")
+	}
+	sums := make([]float64, hop)
+	for i, w := range window {
+		sums[i%hop] += w
+	}
+	for _, v := range sums {
+		if math.Abs(v-sums[0]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// NOLA checks if window and hop satisfies the nonzero overlap-add condition, that is the
+// sum of the squared windows shifted by multiples of hop is larger than tol everywhere.
+// This is the condition for ISTFT to be able to reconstruct the signal
+func NOLA(window []float64, hop int, tol float64) bool {
+	if hop < 1 {
+		panic("stft: The hop has to be positive")
+	}
+	sums := make([]float64, hop)
+	for i, w := range window {
+		sums[i%hop] += w * w
+	}
+	for _, v := range sums {
+		if v <= tol {
+			return false
+		}
+	}
+	return true
+}
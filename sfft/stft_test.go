@@ -0,0 +1,188 @@
+package sfft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/window"
+	"gonum.org/v1/gonum/floats"
+)
+
+// ones returns a slice of length n filled with ones
+func ones(n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = 1.0
+	}
+	return res
+}
+
+// periodicHann returns a periodic Hann window of length n
+func periodicHann(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 0.5 * (1.0 - math.Cos(2.0*math.Pi*float64(i)/float64(n)))
+	}
+	return w
+}
+
+func TestSTFTRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]float64, 301)
+	for i := range data {
+		data[i] = rng.NormFloat64()
+	}
+
+	for i, test := range []struct {
+		window []float64
+		hop    int
+		nfft   int
+		center bool
+	}{
+		{window: periodicHann(32), hop: 8, nfft: 0, center: true},
+		{window: periodicHann(32), hop: 16, nfft: 64, center: true},
+		{window: periodicHann(31), hop: 10, nfft: 33, center: true},
+		{window: []float64{1.0, 1.0, 1.0, 1.0}, hop: 4, nfft: 0, center: false},
+	} {
+		stft := NewSTFT(test.window, test.hop, test.nfft)
+		stft.Center = test.center
+		spec := stft.STFT(data)
+		nr, nc := spec.Dims()
+		nfft := test.nfft
+		if nfft == 0 {
+			nfft = len(test.window)
+		}
+		if nr != stft.NumFrames(len(data)) || nc != nfft/2+1 {
+			t.Errorf("Test #%d: Unexpected dimensions (%d, %d)", i, nr, nc)
+		}
+
+		rec := stft.ISTFT(spec, len(data))
+		if !floats.EqualApprox(rec, data, 1e-10) {
+			t.Errorf("Test #%d: Signal was not reconstructed", i)
+		}
+	}
+}
+
+func TestSTFTMasking(t *testing.T) {
+	n := 512
+	low := make([]float64, n)
+	data := make([]float64, n)
+	for i := range data {
+		low[i] = math.Sin(2.0 * math.Pi * 4.0 * float64(i) / 64.0)
+		data[i] = low[i] + 0.5*math.Sin(2.0*math.Pi*20.0*float64(i)/64.0)
+	}
+
+	stft := NewSTFT(periodicHann(64), 16, 0)
+	spec := stft.STFT(data)
+	nr, nc := spec.Dims()
+	for i := 0; i < nr; i++ {
+		for j := 0; j < nc; j++ {
+			if stft.Freq(j) > 10.0/64.0 {
+				spec.Set(i, j, 0.0)
+			}
+		}
+	}
+	filtered := stft.ISTFT(spec, n)
+
+	// Ignore the edges where the zero-padding affects the result
+	for i := 64; i < n-64; i++ {
+		if math.Abs(filtered[i]-low[i]) > 1e-6 {
+			t.Errorf("Masking failed at %d: Expected %f got %f", i, low[i], filtered[i])
+			break
+		}
+	}
+}
+
+func TestSTFTTime(t *testing.T) {
+	stft := NewSTFT(periodicHann(33), 4, 0)
+	if stft.Time(3) != 12.0 {
+		t.Errorf("Expected frame center 12 got %f", stft.Time(3))
+	}
+	stft.Center = false
+	if stft.Time(0) != 16.0 {
+		t.Errorf("Expected frame center 16 got %f", stft.Time(0))
+	}
+}
+
+func TestCOLA(t *testing.T) {
+	for i, test := range []struct {
+		window []float64
+		hop    int
+		expect bool
+	}{
+		{window: periodicHann(64), hop: 32, expect: true},
+		{window: periodicHann(64), hop: 16, expect: true},
+		{window: window.Hann(ones(64)), hop: 32, expect: false},
+		{window: []float64{1.0, 1.0, 1.0, 1.0}, hop: 4, expect: true},
+		{window: []float64{1.0, 1.0, 1.0, 1.0}, hop: 3, expect: false},
+	} {
+		if COLA(test.window, test.hop, 1e-10) != test.expect {
+			t.Errorf("Test #%d: Expected %v", i, test.expect)
+		}
+	}
+}
+
+func TestNOLA(t *testing.T) {
+	for i, test := range []struct {
+		window []float64
+		hop    int
+		expect bool
+	}{
+		{window: periodicHann(64), hop: 32, expect: true},
+		{window: window.Hann(ones(64)), hop: 32, expect: true},
+		{window: periodicHann(64), hop: 64, expect: false},
+		{window: []float64{1.0, 1.0, 1.0, 1.0}, hop: 3, expect: true},
+		{window: []float64{1.0, 1.0, 1.0, 1.0}, hop: 5, expect: false},
+	} {
+		if NOLA(test.window, test.hop, 1e-10) != test.expect {
+			t.Errorf("Test #%d: Expected %v", i, test.expect)
+		}
+	}
+}
+
+func TestISTFTViolatesNOLA(t *testing.T) {
+	stft := NewSTFT(periodicHann(16), 16, 0)
+	spec := stft.STFT(make([]float64, 64))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic when the NOLA condition is violated")
+		}
+	}()
+	stft.ISTFT(spec, 64)
+}
+
+func TestISTFTRequireCOLA(t *testing.T) {
+	// The symmetric Hann window satisfies NOLA but not COLA for a hop of half its length
+	win := window.Hann(ones(64))
+	if !NOLA(win, 32, 1e-10) || COLA(win, 32, 1e-10) {
+		t.Fatalf("Expected the window to satisfy NOLA and violate COLA")
+	}
+	data := make([]float64, 256)
+	for i := range data {
+		data[i] = math.Sin(0.1 * float64(i))
+	}
+
+	stft := NewSTFT(win, 32, 0)
+	spec := stft.STFT(data)
+	if !floats.EqualApprox(stft.ISTFT(spec, len(data)), data, 1e-10) {
+		t.Errorf("Expected the signal to be reconstructed when only NOLA is required")
+	}
+
+	stft.RequireCOLA = true
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected a panic when COLA is required and violated")
+			}
+		}()
+		stft.ISTFT(spec, len(data))
+	}()
+
+	// A periodic Hann window satisfies COLA
+	stft = NewSTFT(periodicHann(64), 32, 0)
+	stft.RequireCOLA = true
+	if !floats.EqualApprox(stft.ISTFT(stft.STFT(data), len(data)), data, 1e-10) {
+		t.Errorf("Expected the signal to be reconstructed when COLA holds")
+	}
+}
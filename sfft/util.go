@@ -120,3 +120,49 @@ func Center3(data *Mat3) {
 		}
 	}
 }
+
+// freqIndex returns the frequency corresponding to index i of a transform of
+// length n. The spacing is assumed to be 1.0
+func freqIndex(i, n int) float64 {
+	freq := float64(i) / float64(n)
+	if i > n/2 {
+		freq -= 1.0
+	}
+	return freq
+}
+
+// forEachFreq2 calls fn for each element of a row-major array with nr rows and
+// ncStored columns. fr and fc is the frequency along the rows and the columns,
+// respectively, when nc is the number of columns of the transformed array.
+// Thus, ncStored is nc for a full spectrum and nc/2+1 for a half spectrum
+func forEachFreq2(nr, nc, ncStored int, fn func(idx int, fr, fc float64)) {
+	for i := 0; i < nr; i++ {
+		fr := freqIndex(i, nr)
+		for j := 0; j < ncStored; j++ {
+			fn(i*ncStored+j, fr, freqIndex(j, nc))
+		}
+	}
+}
+
+// forEachFreq3 is the 3D equivalent of forEachFreq2. The array is assumed to be
+// stored in the same way as Mat3, fd is the frequency along the depth
+func forEachFreq3(nr, nc, nd, ncStored int, fn func(idx int, fr, fc, fd float64)) {
+	for k := 0; k < nd; k++ {
+		fd := freqIndex(k, nd)
+		for i := 0; i < nr; i++ {
+			fr := freqIndex(i, nr)
+			for j := 0; j < ncStored; j++ {
+				fn(k*nr*ncStored+i*ncStored+j, fr, freqIndex(j, nc), fd)
+			}
+		}
+	}
+}
+
+// nextPow2 returns the smallest power of two that is larger than or equal to n
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}